@@ -0,0 +1,82 @@
+package sthree_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+// fake implements the methods of `s3api.API` used by the test; calling any other one panics.
+type fake struct {
+	s3api.API
+	objects map[string]string
+}
+
+func (f *fake) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	f.objects[aws.StringValue(input.Key)] = aws.StringValue(input.ContentType)
+	return &s3.PutObjectOutput{ETag: aws.String(`"etag"`)}, nil
+}
+
+func (f *fake) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	if _, ok := f.objects[aws.StringValue(input.Key)]; !ok {
+		return nil, awserr.New("NoSuchKey", "missing", nil)
+	}
+
+	return &s3.GetObjectOutput{ContentLength: aws.Int64(4)}, nil
+}
+
+func Test_API(t *testing.T) {
+	api := &fake{objects: map[string]string{}}
+
+	var seen []string
+	client, err := sthree.Open(nil, sthree.WithAPI(api), sthree.WithRegion("us-east-1"))
+	if err != nil {
+		t.Fatalf("failed to open client - %v", err.Error())
+	}
+	client.Use(func(next sthree.Handler) sthree.Handler {
+		return func(ctx aws.Context, op *sthree.Operation) error {
+			seen = append(seen, op.Name)
+			return next(ctx, op)
+		}
+	})
+
+	if client.Sdk != nil || client.Requests != nil {
+		t.Errorf("expected no SDK client nor requests module with a custom API")
+	}
+	if region, _ := client.Region("assets"); region != "us-east-1" {
+		t.Errorf("unexpected region - %v", region)
+	}
+
+	if _, err := client.Bucket("assets").Put("logo.png", "logo"); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if _, ok := api.objects["logo.png"]; !ok {
+		t.Errorf("expected the fake to receive the object")
+	}
+
+	if _, err := client.Bucket("assets").Get("logo.png"); err != nil {
+		t.Errorf("failed to get object - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Get("missing.png"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected not found error - %v", err)
+	}
+
+	if len(seen) != 3 {
+		t.Errorf("expected operations to run through the middleware chain - %v", seen)
+	}
+
+	t.Run("region discovery", func(t *testing.T) {
+		_, err := sthree.Open(nil, sthree.WithAPI(api), sthree.WithRegionDiscovery())
+		if !errors.Is(err, sthree.ErrInvalidConfig) {
+			t.Errorf("expected region discovery to be rejected - %v", err)
+		}
+	})
+}
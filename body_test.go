@@ -0,0 +1,129 @@
+package sthree_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+func Test_Put_Raw(t *testing.T) {
+	client, server := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	bucket := client.Bucket("assets")
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	path := filepath.Join(t.TempDir(), "logo.png")
+	if err := os.WriteFile(path, png, 0o600); err != nil {
+		t.Fatalf("failed to write file - %v", err.Error())
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open file - %v", err.Error())
+	}
+	defer file.Close()
+
+	spooled := t.TempDir()
+	t.Setenv("TMPDIR", spooled)
+
+	large := bytes.Repeat([]byte("sthree"), objects.MaxMemorySpool/6+1)
+
+	cases := []struct {
+		key      string
+		body     any
+		expected []byte
+	}{
+		{"bytes.png", png, png},
+		{"string.txt", "plain text", []byte("plain text")},
+		{"file.png", file, png},
+		{"reader.png", iotest.OneByteReader(bytes.NewReader(png)), png},
+		{"large.bin", io.MultiReader(bytes.NewReader(large)), large},
+		{"struct.json", map[string]int{"version": 3}, []byte(`{"version":3}`)},
+	}
+
+	for _, c := range cases {
+		info, err := bucket.Put(c.key, c.body)
+		if err != nil {
+			t.Errorf("failed to put %v - %v", c.key, err.Error())
+			continue
+		}
+
+		stored, _ := server.Object("assets", c.key)
+		if !bytes.Equal(stored, c.expected) || info.Size != int64(len(c.expected)) {
+			t.Errorf("unexpected body stored for %v - %d bytes, %+v", c.key, len(stored), info)
+		}
+	}
+
+	if entries, _ := os.ReadDir(spooled); len(entries) != 0 {
+		t.Errorf("expected spooled bodies to be removed, found %v", entries)
+	}
+
+	info, err := bucket.Put("raw.png", png)
+	if err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if info.ContentType != "" {
+		t.Errorf("expected raw payloads not to get the content type of a codec, got %v", info.ContentType)
+	}
+}
+
+func Test_Put_Spool(t *testing.T) {
+	client, server := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	spooled := t.TempDir()
+	t.Setenv("TMPDIR", spooled)
+
+	large := func() io.Reader {
+		return io.MultiReader(bytes.NewReader(bytes.Repeat([]byte("sthree"), objects.MaxMemorySpool/6+1)))
+	}
+	leftovers := func(step string) {
+		if entries, _ := os.ReadDir(spooled); len(entries) != 0 {
+			t.Errorf("expected no spooled body after %v, found %v", step, entries)
+		}
+	}
+
+	client.Requests.PutObject(objects.Put{Bucket: "assets", Key: "dropped.bin", Body: large()})
+	leftovers("dropping a request")
+
+	req, _ := client.Requests.PutObject(objects.Put{Bucket: "assets", Key: "presigned.bin", Body: large()})
+	if _, err := req.Presign(time.Minute); err != nil {
+		t.Fatalf("failed to presign request - %v", err.Error())
+	}
+	leftovers("presigning a request")
+
+	// Presigning left the body unread, so the request can still be sent with it.
+	if err := req.Send(); err != nil {
+		t.Fatalf("failed to send request - %v", err.Error())
+	}
+	leftovers("sending a request")
+
+	stored, _ := server.Object("assets", "presigned.bin")
+	if len(stored) != objects.MaxMemorySpool/6*6+6 {
+		t.Errorf("expected the body not to be consumed by presigning, stored %d bytes", len(stored))
+	}
+
+	t.Run("unreadable bodies fail the request", func(t *testing.T) {
+		broken := errors.New("broken reader")
+		input := objects.PutInput("assets", "broken.bin", iotest.ErrReader(broken))
+
+		_, err := client.Sdk.PutObject(input)
+		if err == nil || !strings.Contains(err.Error(), broken.Error()) {
+			t.Errorf("expected read error to fail the request, got %v", err)
+		}
+	})
+}
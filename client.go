@@ -1,7 +1,9 @@
 package sthree
 
 import (
-	"github.com/aws/aws-sdk-go/aws"
+	"errors"
+	"log/slog"
+
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -10,6 +12,13 @@ import (
 	"github.com/avila-r/sthree/internal/buckets"
 	"github.com/avila-r/sthree/internal/objects"
 	"github.com/avila-r/sthree/internal/requests"
+	"github.com/avila-r/sthree/pkg/breaker"
+	"github.com/avila-r/sthree/pkg/dryrun"
+	"github.com/avila-r/sthree/pkg/logging"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/ratelimit"
+	"github.com/avila-r/sthree/pkg/s3api"
+	"github.com/avila-r/sthree/pkg/tracing"
 )
 
 // Sthree represents the core struct for managing interactions with AWS S3.
@@ -18,80 +27,201 @@ type Sthree struct {
 	// @param Provider: The AWS configuration provider (e.g., session or custom provider).
 	Provider client.ConfigProvider
 
-	// @param Sdk: The AWS S3 SDK instance used to interact with the S3 service.
+	// @param Config: The validated configuration the S3 client was built with.
+	Config Config
+
+	// @param Sdk: The AWS S3 SDK instance used to interact with the S3 service; nil when built on a custom API.
 	Sdk *s3.S3
 
+	// @param API: The S3 API the modules are built on; Sdk unless a custom one was configured.
+	API s3api.API
+
 	// @param Buckets: Module for performing bucket-level operations on S3.
 	Buckets *buckets.Module
 
 	// @param Requests: Module for handling various S3 requests.
+	// It is nil when the configured API does not implement `s3api.Requester`.
 	Requests *requests.Module
+
+	// @param Plan: The mutating operations recorded instead of sent; nil unless dry-run mode is enabled.
+	Plan *dryrun.Plan
+
+	// chain is the middleware chain shared by every module derived from this client.
+	chain *middleware.Chain
+
+	// regions routes buckets to per-region clients when region discovery is enabled.
+	regions *regions
 }
 
 // Connect establishes a connection to AWS S3 using the provided ConfigProvider and optional configurations.
 // It creates a new `Sthree` instance with necessary dependencies for interacting with AWS S3.
 //
+// Invalid settings are logged, with `Config.Logger` or the default logger, and fall back to their
+// defaults while the valid ones are still applied; an invalid `Config.Guard` falls back to a
+// read-only policy. Use `Open` to reject invalid settings with an error instead.
+//
 // @param The AWS configuration provider used to create the S3 client.
-// @param Optional configuration settings to customize the AWS connection.
+// @param Optional settings (a `Config` or functional options) to customize the AWS connection.
 // @return An instance of `Sthree` initialized with the AWS SDK and required modules.
-func Connect(provider client.ConfigProvider, cfgs ...Config) *Sthree {
-	s3 := s3.New(provider, unwrapConfig(cfgs...)...)
+func Connect(provider client.ConfigProvider, opts ...Option) *Sthree {
+	cfg, err := newConfig(opts...)
 
-	return &Sthree{
-		Provider: provider,
-		Sdk:      s3,
-		Buckets: &buckets.Module{
-			Sdk: s3,
-		},
-		Requests: &requests.Module{
-			Sdk: s3,
-		},
+	var invalid *ConfigError
+	if errors.As(err, &invalid) {
+		logger := cfg.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.Warn("sthree: ignoring invalid settings, use Open to reject them", "error", err.Error())
+
+		cfg = cfg.withoutInvalid(invalid)
+	}
+
+	return open(provider, cfg)
+}
+
+// Open establishes a connection to AWS S3 like `Connect`, but rejects invalid options.
+//
+// @param The AWS configuration provider used to create the S3 client.
+// @param Optional settings (a `Config` or functional options) to customize the AWS connection.
+// @return An instance of `Sthree`, or a `*ConfigError` listing every invalid setting.
+func Open(provider client.ConfigProvider, opts ...Option) (*Sthree, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
 	}
+
+	return open(provider, cfg), nil
 }
 
 // Session initializes a new session for AWS S3 and returns an instance of `Sthree`.
 // It automatically creates a session with the provided configuration and AWS provider.
 //
-// @param Optional configuration settings to customize the AWS session.
+// @param Optional settings (a `Config` or functional options) to customize the AWS session.
 // @return A pointer to an initialized `Sthree` instance or an error if the session creation fails.
-func Session(cfgs ...Config) (*Sthree, error) {
-	sess, err := session.NewSession(unwrapConfig(cfgs...)...)
+func Session(opts ...Option) (*Sthree, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg.ToAWSConfig(),
+		Profile:           cfg.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return Connect(sess), nil
+	return open(sess, cfg), nil
+}
+
+// open builds the `Sthree` instance and its modules from an already validated configuration.
+func open(provider client.ConfigProvider, cfg Config) *Sthree {
+	api, sdk := cfg.API, (*s3.S3)(nil)
+	if api == nil {
+		sdk = s3.New(provider, cfg.ToAWSConfig())
+		api = sdk
+	}
+	chain := &middleware.Chain{}
+
+	client := &Sthree{
+		Provider: provider,
+		Config:   cfg,
+		Sdk:      sdk,
+		API:      api,
+		Buckets: &buckets.Module{
+			Sdk:      api,
+			Chain:    chain,
+			Upload:   cfg.Upload,
+			Download: cfg.Download,
+		},
+		chain: chain,
+	}
+
+	if requester, ok := api.(s3api.Requester); ok {
+		client.Requests = &requests.Module{
+			Sdk:   requester,
+			Chain: chain,
+		}
+	}
+
+	// The tracer is the outermost middleware, so every other one runs within the span.
+	if cfg.Tracer != nil {
+		chain.Use(tracing.Middleware(cfg.Tracer))
+	}
+
+	if cfg.Logger != nil {
+		chain.Use(logging.Middleware(cfg.Logger, cfg.Logging))
+	}
+
+	if cfg.Metrics != nil {
+		chain.Use(cfg.Metrics.Middleware())
+	}
+
+	// The guard runs first, so protected operations are rejected even in dry-run mode.
+	if cfg.Guard != nil {
+		chain.UseInner(cfg.Guard.Middleware())
+	}
+
+	// Dry-run operations are answered before reaching the breaker and the rate limiter.
+	if cfg.DryRun {
+		client.Plan = dryrun.New()
+		chain.UseInner(client.Plan.Middleware())
+	}
+
+	// Only the operations that are actually sent are audited, including those failed by the breaker.
+	if cfg.Audit != nil {
+		chain.UseInner(cfg.Audit.Middleware())
+	}
+
+	// The breaker runs before the rate limiter, so rejected operations do not consume tokens.
+	if cfg.CircuitBreaker != nil {
+		chain.UseInner(breaker.New(*cfg.CircuitBreaker).Middleware())
+	}
+
+	if cfg.RateLimit != nil {
+		chain.UseInner(ratelimit.New(*cfg.RateLimit).Middleware())
+	}
+
+	if cfg.DiscoverRegions {
+		client.regions = newRegions(provider, cfg, sdk)
+		client.Buckets.Router = client.regions.api
+	}
+
+	return client
 }
 
 // New is an alias for `Connect` and creates a new connection to AWS S3 with the provided ConfigProvider
 // and optional configuration settings.
 //
 // @param The AWS configuration provider used to create the S3 client.
-// @param Optional configuration settings to customize the AWS connection.
+// @param Optional settings (a `Config` or functional options) to customize the AWS connection.
 // @return An instance of `Sthree` initialized with the AWS SDK and required modules.
-func New(provider client.ConfigProvider, cfgs ...Config) *Sthree {
-	return Connect(provider, cfgs...)
+func New(provider client.ConfigProvider, opts ...Option) *Sthree {
+	return Connect(provider, opts...)
 }
 
 // Client is an alias for `Connect` that creates a new connection to AWS S3 with the provided ConfigProvider
 // and optional configuration settings.
 //
 // @param The AWS configuration provider used to create the S3 client.
-// @param Optional configuration settings to customize the AWS connection.
+// @param Optional settings (a `Config` or functional options) to customize the AWS connection.
 // @return An instance of `Sthree` initialized with the AWS SDK and required modules.
-func Client(provider client.ConfigProvider, cfgs ...Config) *Sthree {
-	return Connect(provider, cfgs...)
+func Client(provider client.ConfigProvider, opts ...Option) *Sthree {
+	return Connect(provider, opts...)
 }
 
 // NewClient is an alias for `Connect` that creates a new connection to AWS S3 with the provided ConfigProvider
 // and optional configuration settings.
 //
 // @param The AWS configuration provider used to create the S3 client.
-// @param Optional configuration settings to customize the AWS connection.
+// @param Optional settings (a `Config` or functional options) to customize the AWS connection.
 // @return An instance of `Sthree` initialized with the AWS SDK and required modules.
-func NewClient(provider client.ConfigProvider, cfgs ...Config) *Sthree {
-	return Connect(provider, cfgs...)
+func NewClient(provider client.ConfigProvider, opts ...Option) *Sthree {
+	return Connect(provider, opts...)
 }
 
 // Bucket returns an instance of the `objects.Module` for the specified bucket.
@@ -102,9 +232,14 @@ func NewClient(provider client.ConfigProvider, cfgs ...Config) *Sthree {
 // @param name The name of the bucket to associate with the returned module.
 // @return An instance of `objects.Module` configured with the bucket name and the associated SDK.
 func (m *Sthree) Bucket(bucket string) *objects.Module {
+	sdk := m.sdkFor(bucket)
+
 	return &objects.Module{
-		Bucket: bucket,
-		Sdk:    m.Sdk,
+		Bucket:     bucket,
+		Sdk:        sdk,
+		Uploader:   objects.NewUploader(sdk, m.Config.Upload),
+		Downloader: m.Config.Download,
+		Chain:      m.chain,
 	}
 }
 
@@ -139,23 +274,10 @@ func (m *Sthree) In(bucket string) *objects.Module {
 // @return An instance of `bucket.Module` configured with the bucket name and the associated SDK.
 func (m *Sthree) For(name string) *bucket.Module {
 	return &bucket.Module{
-		Bucket: name,
-		Sdk:    m.Sdk,
+		Bucket:   name,
+		Sdk:      m.sdkFor(name),
+		Chain:    m.chain,
+		Upload:   m.Config.Upload,
+		Download: m.Config.Download,
 	}
 }
-
-// unwrapConfig extracts the AWS configuration settings from the provided Config objects and
-// returns them as a slice of `*aws.Config`. If no configuration is provided, it returns an empty slice.
-//
-// @param Optional configuration objects to customize the AWS connection.
-// @return A slice of `*aws.Config` that can be used to create the AWS S3 client.
-func unwrapConfig(c ...Config) []*aws.Config {
-	if len(c) > 0 {
-		return []*aws.Config{
-			c[0].ToAWSConfig(),
-		}
-	}
-
-	// Empty
-	return []*aws.Config{}
-}
@@ -19,9 +19,9 @@ func Test_Session(t *testing.T) {
 
 	all, _ := client.Buckets.List()
 
-	t.Logf("%v existent buckets:", len(all.Buckets))
+	t.Logf("%v existent buckets:", len(all))
 
-	for i, bucket := range all.Buckets {
-		t.Logf("[%v] Name: %v", i+1, *bucket.Name)
+	for i, bucket := range all {
+		t.Logf("[%v] Name: %v", i+1, bucket.Name)
 	}
 }
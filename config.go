@@ -1,18 +1,437 @@
 package sthree
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 
+	"github.com/avila-r/sthree/pkg/audit"
+	"github.com/avila-r/sthree/pkg/breaker"
+	"github.com/avila-r/sthree/pkg/guard"
+	"github.com/avila-r/sthree/pkg/logging"
+	"github.com/avila-r/sthree/pkg/metrics"
 	"github.com/avila-r/sthree/pkg/pointer"
+	"github.com/avila-r/sthree/pkg/ratelimit"
+	"github.com/avila-r/sthree/pkg/retry"
+	"github.com/avila-r/sthree/pkg/s3api"
+	"github.com/avila-r/sthree/pkg/tracing"
 )
 
+// Config holds the settings used to build the AWS S3 client wrapped by `Sthree`.
+//
+// The first-class fields cover the common cases (regions, S3-compatible endpoints such as
+// MinIO or LocalStack, credentials and HTTP tuning). Anything else can still be set through
+// `Advanced`, which is used as the base configuration and overridden by the fields above.
 type Config struct {
-	Region   string
-	Advanced aws.Config
+	// Region is the AWS region the client sends requests to (e.g., "us-east-1").
+	Region string `yaml:"region"`
+
+	// Endpoint overrides the S3 endpoint URL (e.g., "http://localhost:9000" for MinIO).
+	Endpoint string `yaml:"endpoint"`
+
+	// ForcePathStyle addresses buckets as "endpoint/bucket" instead of "bucket.endpoint",
+	// which is required by most S3-compatible providers.
+	ForcePathStyle bool `yaml:"force_path_style"`
+
+	// DisableSSL sends requests over plain HTTP when the endpoint has no scheme.
+	DisableSSL bool `yaml:"disable_ssl"`
+
+	// DiscoverRegions discovers the region of each bucket and routes its requests
+	// through a per-region client, so cross-region buckets work transparently.
+	DiscoverRegions bool `yaml:"discover_regions"`
+
+	// API replaces the AWS S3 SDK client the modules are built on, e.g. with a fake in tests.
+	// The unsent requests of `Sthree.Requests` are only available if it also implements `s3api.Requester`.
+	API s3api.API `yaml:"-"`
+
+	// Profile is the name of the AWS shared-config profile to read credentials and region from.
+	Profile string `yaml:"profile"`
+
+	// Credentials selects how the client authenticates against S3.
+	// When left empty, the default AWS credential chain is used.
+	Credentials Credentials `yaml:"credentials"`
+
+	// HTTPClient is the HTTP client used to send requests.
+	// It cannot be combined with the connection-pool settings below.
+	HTTPClient *http.Client `yaml:"-"`
+
+	// MaxIdleConns limits the number of idle connections kept across all hosts.
+	MaxIdleConns int `yaml:"max_idle_conns"`
+
+	// MaxIdleConnsPerHost limits the number of idle connections kept per host.
+	MaxIdleConnsPerHost int `yaml:"max_idle_conns_per_host"`
+
+	// MaxConnsPerHost limits the total number of connections per host.
+	MaxConnsPerHost int `yaml:"max_conns_per_host"`
+
+	// IdleConnTimeout is how long an idle connection is kept before being closed.
+	IdleConnTimeout time.Duration `yaml:"idle_conn_timeout"`
+
+	// Retry replaces the default retryer of the AWS SDK for every call of the client.
+	// Calls of `objects.Module` can still override it through their `Retry` parameter.
+	Retry *retry.Policy `yaml:"retry"`
+
+	// Upload tunes the multipart uploads of `objects.Module.Upload`: part size, concurrency
+	// and whether the parts of failed uploads are kept.
+	Upload UploadConfig `yaml:"upload"`
+
+	// Download tunes the ranged downloads of `objects.Module.Download`: part size and concurrency.
+	Download DownloadConfig `yaml:"download"`
+
+	// RateLimit throttles the operations of the client on the client side, globally,
+	// per bucket and per key prefix, for reads and writes separately.
+	RateLimit *ratelimit.Config `yaml:"rate_limit"`
+
+	// CircuitBreaker fails operations fast, with an `errs.ErrCircuitOpen` error, while
+	// too many operations against the endpoint or bucket are failing.
+	CircuitBreaker *breaker.Config `yaml:"circuit_breaker"`
+
+	// Guard rejects mutating operations, with an `errs.ErrProtected` error, in read-only mode
+	// or when they would delete or overwrite protected buckets or keys.
+	Guard *guard.Policy `yaml:"guard"`
+
+	// Audit records every mutating operation sent by the client, successful or not.
+	Audit *audit.Auditor `yaml:"-"`
+
+	// DryRun validates and records the mutating operations of the client in `Sthree.Plan`
+	// instead of sending them. Read operations are still sent.
+	DryRun bool `yaml:"dry_run"`
+
+	// Tracer records a span for every S3 operation, with nested spans for the requests
+	// of composite operations such as multipart uploads.
+	Tracer tracing.Tracer `yaml:"-"`
+
+	// Metrics counts the operations of the client, with their latency and bytes transferred.
+	// A single collector can be shared by several clients.
+	Metrics *metrics.Collector `yaml:"-"`
+
+	// Logger receives a structured record for every S3 operation, and the redacted
+	// debug output of the AWS SDK when `Advanced.LogLevel` enables it.
+	Logger *slog.Logger `yaml:"-"`
+
+	// Logging tunes the records written to Logger.
+	Logging logging.Options `yaml:"logging"`
+
+	// Advanced is the raw AWS configuration used as a base for every other setting.
+	Advanced aws.Config `yaml:"-"`
+}
+
+// Credentials describes the credentials used by the S3 client.
+type Credentials struct {
+	// AccessKeyID is the access key of static credentials.
+	AccessKeyID string `yaml:"access_key_id"`
+
+	// SecretAccessKey is the secret key of static credentials.
+	SecretAccessKey string `yaml:"secret_access_key"`
+
+	// SessionToken is the optional session token of temporary static credentials.
+	SessionToken string `yaml:"session_token"`
+
+	// FromEnv loads the credentials from the AWS_* environment variables.
+	FromEnv bool `yaml:"from_env"`
+}
+
+// Validate checks every setting of the configuration and reports all the invalid ones at once.
+//
+// @return A `*ConfigError` listing each invalid field, or nil if the configuration is valid.
+func (c Config) Validate() error {
+	problems := []FieldError{}
+	invalid := func(field, reason string) {
+		problems = append(problems, FieldError{Field: field, Reason: reason})
+	}
+
+	if c.Endpoint != "" {
+		if endpoint, err := url.Parse(c.Endpoint); err != nil {
+			invalid("Endpoint", err.Error())
+		} else if endpoint.Scheme != "" && endpoint.Host == "" {
+			invalid("Endpoint", "missing host")
+		} else if endpoint.Scheme != "" && endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+			invalid("Endpoint", fmt.Sprintf("unsupported scheme %q", endpoint.Scheme))
+		}
+	}
+
+	if strings.ContainsAny(c.Region, " /") {
+		invalid("Region", fmt.Sprintf("malformed region %q", c.Region))
+	}
+
+	if c.API != nil && c.DiscoverRegions {
+		invalid("DiscoverRegions", "cannot be combined with a custom API")
+	}
+
+	creds := c.Credentials
+	if creds.FromEnv && (creds.AccessKeyID != "" || creds.SecretAccessKey != "") {
+		invalid("Credentials", "static and environment credentials are mutually exclusive")
+	}
+	if (creds.AccessKeyID == "") != (creds.SecretAccessKey == "") {
+		invalid("Credentials", "both access key ID and secret access key are required")
+	}
+	if c.Profile != "" && (creds.FromEnv || creds.AccessKeyID != "") {
+		invalid("Profile", "cannot be combined with explicit credentials")
+	}
+	if creds.SessionToken != "" && creds.AccessKeyID == "" {
+		invalid("Credentials", "session token requires static credentials")
+	}
+
+	if c.MaxIdleConns < 0 {
+		invalid("MaxIdleConns", "must not be negative")
+	}
+	if c.MaxIdleConnsPerHost < 0 {
+		invalid("MaxIdleConnsPerHost", "must not be negative")
+	}
+	if c.MaxConnsPerHost < 0 {
+		invalid("MaxConnsPerHost", "must not be negative")
+	}
+	if c.IdleConnTimeout < 0 {
+		invalid("IdleConnTimeout", "must not be negative")
+	}
+	if c.HTTPClient != nil && c.tunesPool() {
+		invalid("HTTPClient", "cannot be combined with connection-pool settings")
+	}
+
+	if r := c.Retry; r != nil {
+		if r.MaxAttempts < 0 {
+			invalid("Retry.MaxAttempts", "must not be negative")
+		}
+		if r.BaseDelay < 0 || r.MaxDelay < 0 {
+			invalid("Retry", "delays must not be negative")
+		}
+		if r.MaxDelay > 0 && r.MaxDelay < r.BaseDelay {
+			invalid("Retry.MaxDelay", "must not be lower than the base delay")
+		}
+	}
+
+	if c.Upload.PartSize != 0 && (c.Upload.PartSize < s3manager.MinUploadPartSize || c.Upload.PartSize > MaxUploadPartSize) {
+		invalid("Upload.PartSize", fmt.Sprintf("must be between %d and %d bytes", s3manager.MinUploadPartSize, MaxUploadPartSize))
+	}
+	if c.Upload.Concurrency < 0 {
+		invalid("Upload.Concurrency", "must not be negative")
+	}
+	if c.Download.PartSize < 0 {
+		invalid("Download.PartSize", "must not be negative")
+	}
+	if c.Download.Concurrency < 0 {
+		invalid("Download.Concurrency", "must not be negative")
+	}
+
+	if r := c.RateLimit; r != nil {
+		limits := map[string]ratelimit.Limit{
+			"Read.Global": r.Read.Global, "Read.Bucket": r.Read.Bucket, "Read.Prefix": r.Read.Prefix,
+			"Write.Global": r.Write.Global, "Write.Bucket": r.Write.Bucket, "Write.Prefix": r.Write.Prefix,
+		}
+		for _, scope := range []string{"Read.Global", "Read.Bucket", "Read.Prefix", "Write.Global", "Write.Bucket", "Write.Prefix"} {
+			if limit := limits[scope]; limit.Rate < 0 || limit.Burst < 0 {
+				invalid("RateLimit."+scope, "rate and burst must not be negative")
+			}
+		}
+	}
+
+	if b := c.CircuitBreaker; b != nil {
+		if b.Scope != "" && b.Scope != breaker.Endpoint && b.Scope != breaker.Bucket {
+			invalid("CircuitBreaker.Scope", fmt.Sprintf("unknown scope %q", b.Scope))
+		}
+		if b.FailureRate < 0 || b.FailureRate > 1 {
+			invalid("CircuitBreaker.FailureRate", "must be between 0 and 1")
+		}
+		if b.Window < 0 || b.CoolDown < 0 {
+			invalid("CircuitBreaker", "window and cool-down must not be negative")
+		}
+		if b.MinRequests < 0 || b.HalfOpenRequests < 0 {
+			invalid("CircuitBreaker", "request counts must not be negative")
+		}
+	}
+
+	if g := c.Guard; g != nil {
+		rules := map[string]guard.Rules{"Allow": g.Allow, "Deny": g.Deny}
+		for _, list := range []string{"Allow", "Deny"} {
+			for _, pattern := range rules[list].Buckets {
+				if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+					invalid("Guard."+list+".Buckets", fmt.Sprintf("malformed pattern %q", pattern))
+				}
+			}
+			for _, prefix := range rules[list].Prefixes {
+				if prefix == "" {
+					invalid("Guard."+list+".Prefixes", "must not contain empty prefixes")
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Fields: problems}
+	}
+
+	return nil
 }
 
+// withoutInvalid returns the configuration without the settings reported by the error,
+// which fall back to their defaults. An invalid guard policy falls back to a read-only one
+// rather than to no policy, so that a malformed safety policy never allows more operations.
+func (c Config) withoutInvalid(err *ConfigError) Config {
+	for _, f := range err.Fields {
+		switch f.Field {
+		case "Upload.PartSize":
+			c.Upload.PartSize = 0
+			continue
+		case "Upload.Concurrency":
+			c.Upload.Concurrency = 0
+			continue
+		case "Download.PartSize":
+			c.Download.PartSize = 0
+			continue
+		case "Download.Concurrency":
+			c.Download.Concurrency = 0
+			continue
+		}
+
+		field, _, _ := strings.Cut(f.Field, ".")
+		switch field {
+		case "Endpoint":
+			c.Endpoint = ""
+		case "Region":
+			c.Region = ""
+		case "DiscoverRegions":
+			c.DiscoverRegions = false
+		case "Credentials":
+			c.Credentials = Credentials{}
+		case "Profile":
+			c.Profile = ""
+		case "MaxIdleConns":
+			c.MaxIdleConns = 0
+		case "MaxIdleConnsPerHost":
+			c.MaxIdleConnsPerHost = 0
+		case "MaxConnsPerHost":
+			c.MaxConnsPerHost = 0
+		case "IdleConnTimeout":
+			c.IdleConnTimeout = 0
+		case "HTTPClient":
+			c.MaxIdleConns, c.MaxIdleConnsPerHost, c.MaxConnsPerHost, c.IdleConnTimeout = 0, 0, 0, 0
+		case "Retry":
+			c.Retry = nil
+		case "RateLimit":
+			c.RateLimit = nil
+		case "CircuitBreaker":
+			c.CircuitBreaker = nil
+		case "Guard":
+			c.Guard = &guard.Policy{ReadOnly: true}
+		}
+	}
+
+	return c
+}
+
+// ToAWSConfig converts the configuration into an AWS SDK-compatible `*aws.Config`.
+//
+// `Advanced` is used as the base configuration, and every non-zero field of `Config` overrides it.
+//
+// @return A pointer to an `aws.Config` that can be used to create sessions and S3 clients.
 func (c Config) ToAWSConfig() *aws.Config {
-	cfg := c.Advanced
-	cfg.Region = pointer.NotBlank(c.Region)
-	return &c.Advanced
+	cfg := c.Advanced.Copy()
+
+	if c.Region != "" {
+		cfg.Region = pointer.NotBlank(c.Region)
+	}
+	if c.Endpoint != "" {
+		cfg.Endpoint = pointer.NotBlank(c.Endpoint)
+	}
+	if c.ForcePathStyle {
+		cfg.S3ForcePathStyle = aws.Bool(true)
+	}
+	if c.DisableSSL {
+		cfg.DisableSSL = aws.Bool(true)
+	}
+
+	switch creds := c.Credentials; {
+	case creds.FromEnv:
+		cfg.Credentials = credentials.NewEnvCredentials()
+	case creds.AccessKeyID != "":
+		cfg.Credentials = credentials.NewStaticCredentials(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken)
+	case c.Profile != "":
+		cfg.Credentials = credentials.NewSharedCredentials("", c.Profile)
+	}
+
+	if c.Retry != nil {
+		request.WithRetryer(cfg, c.Retry.Retryer())
+		cfg.EnforceShouldRetryCheck = aws.Bool(true)
+	}
+
+	if c.Logger != nil {
+		cfg.Logger = logging.SDKLogger(c.Logger)
+	}
+
+	if c.HTTPClient != nil {
+		cfg.HTTPClient = c.HTTPClient
+	} else if c.tunesPool() {
+		cfg.HTTPClient = c.pooledClient()
+	}
+
+	return cfg
+}
+
+// tunesPool reports whether any connection-pool setting was provided.
+func (c Config) tunesPool() bool {
+	return c.MaxIdleConns != 0 ||
+		c.MaxIdleConnsPerHost != 0 ||
+		c.MaxConnsPerHost != 0 ||
+		c.IdleConnTimeout != 0
+}
+
+// pooledClient builds an HTTP client whose transport applies the connection-pool settings.
+func (c Config) pooledClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if c.MaxIdleConns > 0 {
+		transport.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = c.MaxConnsPerHost
+	}
+	if c.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = c.IdleConnTimeout
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// FieldError describes a single invalid configuration field.
+type FieldError struct {
+	// Field is the name of the invalid field.
+	Field string
+
+	// Reason explains why the field is invalid.
+	Reason string
+}
+
+// ConfigError is returned when a configuration has one or more invalid fields.
+type ConfigError struct {
+	// Fields lists every invalid field found.
+	Fields []FieldError
+}
+
+// ErrInvalidConfig is matched by every `*ConfigError` through `errors.Is`.
+var ErrInvalidConfig = errors.New("sthree: invalid config")
+
+func (e *ConfigError) Error() string {
+	reasons := []string{}
+	for _, f := range e.Fields {
+		reasons = append(reasons, f.Field+": "+f.Reason)
+	}
+
+	return ErrInvalidConfig.Error() + " - " + strings.Join(reasons, "; ")
+}
+
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
 }
@@ -0,0 +1,99 @@
+package sthree_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/pkg/guard"
+)
+
+func Test_ConfigOptions(t *testing.T) {
+	sess := session.Must(session.NewSession())
+
+	t.Run("options are applied to the sdk client", func(t *testing.T) {
+		client, err := sthree.Open(sess,
+			sthree.WithRegion("us-west-2"),
+			sthree.WithEndpoint("http://localhost:9000"),
+			sthree.WithPathStyle(),
+			sthree.WithStaticCredentials("id", "secret", ""),
+			sthree.WithConnectionPool(10, 5, 20, time.Minute),
+		)
+		if err != nil {
+			t.Fatalf("failed to open client - %v", err.Error())
+		}
+
+		cfg := client.Sdk.Config
+		if region := aws.StringValue(cfg.Region); region != "us-west-2" {
+			t.Errorf("unexpected region - %v", region)
+		}
+		if endpoint := client.Sdk.Endpoint; endpoint != "http://localhost:9000" {
+			t.Errorf("unexpected endpoint - %v", endpoint)
+		}
+		if !aws.BoolValue(cfg.S3ForcePathStyle) {
+			t.Errorf("expected path-style addressing")
+		}
+		if value, _ := cfg.Credentials.Get(); value.AccessKeyID != "id" {
+			t.Errorf("unexpected access key - %v", value.AccessKeyID)
+		}
+	})
+
+	t.Run("config value keeps working as an option", func(t *testing.T) {
+		client := sthree.Connect(sess, sthree.Config{Region: "sa-east-1"})
+
+		if region := aws.StringValue(client.Sdk.Config.Region); region != "sa-east-1" {
+			t.Errorf("unexpected region - %v", region)
+		}
+	})
+
+	t.Run("every invalid field is reported", func(t *testing.T) {
+		_, err := sthree.Open(sess,
+			sthree.WithEndpoint("ftp://localhost"),
+			sthree.WithStaticCredentials("id", "", ""),
+			sthree.WithConnectionPool(-1, 0, 0, 0),
+		)
+
+		if !errors.Is(err, sthree.ErrInvalidConfig) {
+			t.Fatalf("expected invalid config error - %v", err)
+		}
+
+		var cfgErr *sthree.ConfigError
+		if errors.As(err, &cfgErr); len(cfgErr.Fields) != 3 {
+			t.Errorf("expected 3 invalid fields - %v", err.Error())
+		}
+	})
+
+	t.Run("connect ignores invalid settings", func(t *testing.T) {
+		var logs bytes.Buffer
+		client := sthree.Connect(sess,
+			sthree.WithRegion("eu-west-1"),
+			sthree.WithEndpoint("ftp://localhost"),
+			sthree.WithConnectionPool(-1, 5, 0, 0),
+			sthree.WithGuard(guard.Policy{Deny: guard.Rules{Buckets: []string{"prod-["}}}),
+			sthree.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		)
+
+		if region := aws.StringValue(client.Sdk.Config.Region); region != "eu-west-1" {
+			t.Errorf("expected valid settings to be applied, got region %v", region)
+		}
+		if err := client.Config.Validate(); err != nil {
+			t.Errorf("expected invalid settings to fall back to their defaults - %v", err.Error())
+		}
+		if client.Config.MaxIdleConnsPerHost != 5 || client.Config.Endpoint != "" {
+			t.Errorf("unexpected config - %+v", client.Config)
+		}
+		if client.Config.Guard == nil || !client.Config.Guard.ReadOnly {
+			t.Errorf("expected invalid guard policy to fall back to read-only - %+v", client.Config.Guard)
+		}
+		if !strings.Contains(logs.String(), "Open") || !strings.Contains(logs.String(), "Endpoint") {
+			t.Errorf("expected invalid settings to be logged - %v", logs.String())
+		}
+	})
+}
@@ -0,0 +1,318 @@
+package sthree
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// MaxClockSkew is the largest difference between the local clock and the clock of S3
+// that `Diagnose` accepts. S3 rejects signatures made more than 15 minutes off.
+const MaxClockSkew = 5 * time.Minute
+
+// CanaryPrefix is the prefix of the canary objects written and deleted by `Diagnose`.
+const CanaryPrefix = ".sthree-diagnose/"
+
+// Names of the checks run by `Diagnose`, in order.
+const (
+	CheckCredentials = "credentials"
+	CheckEndpoint    = "endpoint"
+	CheckClock       = "clock"
+	CheckBucket      = "bucket"
+	CheckWrite       = "write"
+	CheckRead        = "read"
+	CheckDelete      = "delete"
+)
+
+// CheckStatus is the outcome of a diagnostic check.
+type CheckStatus string
+
+const (
+	// CheckPassed means the check succeeded.
+	CheckPassed CheckStatus = "passed"
+
+	// CheckFailed means the check failed; its error explains why.
+	CheckFailed CheckStatus = "failed"
+
+	// CheckSkipped means the check was not run, because a check it depends on failed or in dry-run mode,
+	// or that it could not be verified; its detail explains why.
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// errUnverified is returned by checks that could not verify what they check, to report them as skipped.
+var errUnverified = errors.New("unverified")
+
+// Check is the outcome of a single diagnostic check.
+type Check struct {
+	// Name is the name of the check (e.g., "credentials").
+	Name string `json:"name"`
+
+	// Status is the outcome of the check.
+	Status CheckStatus `json:"status"`
+
+	// Detail describes what was verified, if anything worth reporting.
+	Detail string `json:"detail,omitempty"`
+
+	// Error is the reason the check failed.
+	Error string `json:"error,omitempty"`
+
+	// Duration is the time spent running the check.
+	Duration time.Duration `json:"duration"`
+
+	err error
+}
+
+// Report is the structured result of `Diagnose`, serializable to JSON.
+type Report struct {
+	// Bucket is the diagnosed bucket.
+	Bucket string `json:"bucket"`
+
+	// Endpoint is the S3 endpoint the client sends requests to, if overridden.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region is the region of the bucket, if it could be determined.
+	Region string `json:"region,omitempty"`
+
+	// ClockSkew is the difference between the clock of S3 and the local clock.
+	ClockSkew time.Duration `json:"clock_skew"`
+
+	// Checks are the checks run, in order.
+	Checks []Check `json:"checks"`
+}
+
+// Healthy reports whether no check failed.
+func (r *Report) Healthy() bool {
+	return r.Err() == nil
+}
+
+// Err joins the errors of every failed check.
+//
+// @return An error describing every failed check, or nil if none failed.
+func (r *Report) Err() error {
+	failures := []error{}
+	for _, check := range r.Checks {
+		if check.Status == CheckFailed {
+			failures = append(failures, fmt.Errorf("%s: %w", check.Name, check.err))
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+// Ping verifies that S3 is reachable and accepts the credentials of the client,
+// by listing the buckets. It is meant for liveness and readiness probes.
+//
+// @param ctx The context of the request.
+// @return An error if S3 cannot be reached or rejects the request.
+func (m *Sthree) Ping(ctx context.Context) error {
+	_, err := m.Buckets.ListWithContext(ctx)
+	return err
+}
+
+// Diagnose verifies the connectivity of the client to a bucket step by step: the credentials,
+// the reachability of the endpoint, the clock skew, the existence and region of the bucket,
+// and the permissions to write, read and delete objects, with a canary object under `CanaryPrefix`.
+//
+// The credentials check fails when S3 rejects them, not only when they cannot be resolved.
+// Checks that depend on a failed check are skipped, and so are the object checks in dry-run mode
+// and when the guard of the client rejects writes to the bucket (e.g., read-only clients).
+// The endpoint check is skipped when the API of the client does not report response statuses.
+//
+// @param ctx The context of the requests.
+// @param bucket The bucket to diagnose.
+// @return The report of every check, and an error joining the failed checks, if any.
+func (m *Sthree) Diagnose(ctx context.Context, bucket string) (*Report, error) {
+	report := &Report{Bucket: bucket, Endpoint: m.Config.Endpoint}
+
+	run := func(name string, check func() (string, error)) bool {
+		start := time.Now()
+		detail, err := check()
+
+		result := Check{Name: name, Status: CheckPassed, Detail: detail, Duration: time.Since(start), err: err}
+		switch {
+		case errors.Is(err, errUnverified):
+			result.Status, result.err, err = CheckSkipped, nil, nil
+		case err != nil:
+			result.Status, result.Error = CheckFailed, err.Error()
+		}
+		report.Checks = append(report.Checks, result)
+
+		return err == nil
+	}
+	skip := func(names ...string) {
+		for _, name := range names {
+			report.Checks = append(report.Checks, Check{Name: name, Status: CheckSkipped})
+		}
+	}
+
+	// Listing the buckets proves both that the endpoint is reachable and that it accepts the credentials.
+	var date time.Time
+	probe := &middleware.Operation{
+		Name:    middleware.ListBuckets,
+		Input:   &s3.ListBucketsInput{},
+		Options: []request.Option{header("Date", func(value string) { date, _ = http.ParseTime(value) })},
+	}
+	_, probeErr := middleware.Invoke(ctx, m.chain, probe, m.API.ListBucketsWithContext)
+
+	authenticated := run(CheckCredentials, func() (string, error) {
+		var e *errs.Error
+		if errors.As(probeErr, &e) && rejected[e.Code] {
+			return "", fmt.Errorf("rejected by S3: %w", probeErr)
+		}
+
+		if m.Sdk == nil || m.Sdk.Config.Credentials == nil {
+			return "not managed by the client", nil
+		}
+
+		value, err := m.Sdk.Config.Credentials.GetWithContext(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return "resolved from " + value.ProviderName, nil
+	})
+
+	reachable := run(CheckEndpoint, func() (string, error) {
+		if probe.StatusCode == 0 && probeErr != nil {
+			return "", probeErr
+		}
+		if probe.StatusCode == 0 {
+			// The listing succeeded, but the API of the client does not report the status of its responses.
+			return "listed the buckets, but the response status is unknown", errUnverified
+		}
+
+		// Any response proves the endpoint is reachable; denied listings are common with scoped credentials.
+		return fmt.Sprintf("responded with status %d", probe.StatusCode), nil
+	})
+	if !reachable {
+		skip(CheckClock, CheckBucket, CheckWrite, CheckRead, CheckDelete)
+		return report, report.Err()
+	}
+
+	run(CheckClock, func() (string, error) {
+		if date.IsZero() {
+			return "no date returned by the endpoint", nil
+		}
+
+		// The Date header has a one-second resolution.
+		report.ClockSkew = time.Until(date).Truncate(time.Second)
+		if report.ClockSkew > MaxClockSkew || report.ClockSkew < -MaxClockSkew {
+			return "", fmt.Errorf("local clock is off by %s", -report.ClockSkew)
+		}
+
+		return fmt.Sprintf("off by %s", -report.ClockSkew), nil
+	})
+
+	if !authenticated {
+		skip(CheckBucket, CheckWrite, CheckRead, CheckDelete)
+		return report, report.Err()
+	}
+
+	exists := run(CheckBucket, func() (string, error) {
+		region := ""
+		op := &middleware.Operation{
+			Name:    middleware.HeadBucket,
+			Bucket:  bucket,
+			Input:   &s3.HeadBucketInput{Bucket: aws.String(bucket)},
+			Options: []request.Option{header("X-Amz-Bucket-Region", func(value string) { region = value })},
+		}
+
+		_, err := middleware.Invoke(ctx, m.chain, op, m.sdkFor(bucket).HeadBucketWithContext)
+		if region == "" {
+			region, _ = m.RegionWithContext(ctx, bucket)
+		}
+		report.Region = region
+
+		if err != nil {
+			return "", err
+		}
+
+		return "exists in " + region, nil
+	})
+	if !exists {
+		skip(CheckWrite, CheckRead, CheckDelete)
+		return report, report.Err()
+	}
+
+	canary := CanaryPrefix + random()
+
+	// The canary object would only be recorded, not written, or would be rejected by the guard.
+	if m.Plan != nil || (m.Config.Guard != nil && m.Config.Guard.Check(middleware.PutObject, bucket, canary) != nil) {
+		skip(CheckWrite, CheckRead, CheckDelete)
+		return report, report.Err()
+	}
+
+	module := m.Bucket(bucket)
+
+	written := run(CheckWrite, func() (string, error) {
+		_, err := module.PutWithContext(ctx, canary, canary)
+		return "wrote " + canary, err
+	})
+	if !written {
+		skip(CheckRead, CheckDelete)
+		return report, report.Err()
+	}
+
+	run(CheckRead, func() (string, error) {
+		object, err := module.GetWithContext(ctx, canary)
+		if err != nil {
+			return "", err
+		}
+		defer object.Body.Close()
+
+		if _, err := io.Copy(io.Discard, object.Body); err != nil {
+			return "", err
+		}
+
+		return "read " + canary, nil
+	})
+
+	run(CheckDelete, func() (string, error) {
+		_, err := module.DeleteWithContext(ctx, canary)
+		return "deleted " + canary, err
+	})
+
+	return report, report.Err()
+}
+
+// rejected lists the error codes of S3 for credentials it does not accept.
+var rejected = map[string]bool{
+	"InvalidAccessKeyId":    true,
+	"SignatureDoesNotMatch": true,
+	"ExpiredToken":          true,
+	"InvalidToken":          true,
+	"TokenRefreshRequired":  true,
+}
+
+// header returns a request option that passes the value of a response header to fn, if present.
+func header(name string, fn func(value string)) request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			if r.HTTPResponse != nil {
+				if value := r.HTTPResponse.Header.Get(name); value != "" {
+					fn(value)
+				}
+			}
+		})
+	}
+}
+
+// random returns a random hexadecimal identifier.
+func random() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
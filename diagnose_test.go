@@ -0,0 +1,176 @@
+package sthree_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+func Test_Ping(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("failed to ping - %v", err.Error())
+	}
+}
+
+func Test_Diagnose(t *testing.T) {
+	client, server := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	report, err := client.Diagnose(context.Background(), "assets")
+	if err != nil {
+		t.Fatalf("failed to diagnose - %v", err.Error())
+	}
+
+	expected := []string{
+		sthree.CheckCredentials, sthree.CheckEndpoint, sthree.CheckClock, sthree.CheckBucket,
+		sthree.CheckWrite, sthree.CheckRead, sthree.CheckDelete,
+	}
+	if len(report.Checks) != len(expected) {
+		t.Fatalf("expected %v checks, got %+v", len(expected), report.Checks)
+	}
+	for i, check := range report.Checks {
+		if check.Name != expected[i] || check.Status != sthree.CheckPassed {
+			t.Errorf("unexpected check - %+v", check)
+		}
+	}
+
+	if !report.Healthy() || report.Region != "us-east-1" {
+		t.Errorf("unexpected report - %+v", report)
+	}
+
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request, "PUT /assets/"+sthree.CanaryPrefix) {
+			key := strings.TrimPrefix(request, "PUT /assets/")
+			if _, ok := server.Object("assets", key); ok {
+				t.Errorf("canary object %v was not deleted", key)
+			}
+		}
+	}
+
+	if _, err := json.Marshal(report); err != nil {
+		t.Errorf("failed to marshal report - %v", err.Error())
+	}
+}
+
+func Test_Diagnose_MissingBucket(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	report, err := client.Diagnose(context.Background(), "missing")
+	if err == nil {
+		t.Fatalf("expected diagnose to fail")
+	}
+
+	statuses := map[string]sthree.CheckStatus{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+
+	if statuses[sthree.CheckEndpoint] != sthree.CheckPassed {
+		t.Errorf("expected the endpoint check to pass - %+v", report.Checks)
+	}
+	if statuses[sthree.CheckBucket] != sthree.CheckFailed {
+		t.Errorf("expected the bucket check to fail - %+v", report.Checks)
+	}
+	if statuses[sthree.CheckWrite] != sthree.CheckSkipped || statuses[sthree.CheckDelete] != sthree.CheckSkipped {
+		t.Errorf("expected the object checks to be skipped - %+v", report.Checks)
+	}
+}
+
+func Test_Diagnose_RejectedCredentials(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("<Error><Code>SignatureDoesNotMatch</Code><Message>wrong secret</Message></Error>"))
+	})
+
+	client, _ := mock.Connect(t, handler, sthree.WithStaticCredentials("id", "wrong", ""))
+
+	report, err := client.Diagnose(context.Background(), "assets")
+	if err == nil {
+		t.Fatalf("expected diagnose to fail")
+	}
+
+	statuses := map[string]sthree.CheckStatus{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+
+	if statuses[sthree.CheckCredentials] != sthree.CheckFailed {
+		t.Errorf("expected the credentials check to fail - %+v", report.Checks)
+	}
+	if statuses[sthree.CheckEndpoint] != sthree.CheckPassed || statuses[sthree.CheckBucket] != sthree.CheckSkipped {
+		t.Errorf("expected the endpoint to be reachable and the bucket check skipped - %+v", report.Checks)
+	}
+}
+
+func Test_Diagnose_ReadOnly(t *testing.T) {
+	client, server := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	readOnly, err := sthree.Open(session.Must(session.NewSession()), append(mock.Options(server.URL), sthree.WithReadOnly())...)
+	if err != nil {
+		t.Fatalf("failed to open client - %v", err.Error())
+	}
+
+	report, err := readOnly.Diagnose(context.Background(), "assets")
+	if err != nil {
+		t.Fatalf("expected read-only client to be healthy - %v", err.Error())
+	}
+
+	for _, check := range report.Checks {
+		switch check.Name {
+		case sthree.CheckWrite, sthree.CheckRead, sthree.CheckDelete:
+			if check.Status != sthree.CheckSkipped {
+				t.Errorf("expected the object checks to be skipped - %+v", check)
+			}
+		}
+	}
+}
+
+func Test_Diagnose_UnknownStatus(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	// Answers the listing without a status, as APIs that do not report it do.
+	client.Use(func(next sthree.Handler) sthree.Handler {
+		return func(ctx context.Context, op *sthree.Operation) error {
+			if op.Name != "ListBuckets" {
+				return next(ctx, op)
+			}
+			op.Output = &s3.ListBucketsOutput{}
+			return nil
+		}
+	})
+
+	report, err := client.Diagnose(context.Background(), "assets")
+	if err != nil {
+		t.Fatalf("failed to diagnose - %v", err.Error())
+	}
+
+	for _, check := range report.Checks {
+		if check.Name == sthree.CheckEndpoint && (check.Status != sthree.CheckSkipped || check.Detail == "") {
+			t.Errorf("expected the endpoint check to be skipped with a detail - %+v", check)
+		}
+		if check.Name == sthree.CheckBucket && check.Status != sthree.CheckPassed {
+			t.Errorf("expected the bucket check to run - %+v", check)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package sthree_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/ratelimit"
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+// replaced serves the ranges after the first one from a replaced object.
+type replaced struct {
+	s3api.API
+	calls atomic.Int32
+}
+
+func (r *replaced) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	output, err := r.API.GetObjectWithContext(ctx, input, opts...)
+	if err == nil && r.calls.Add(1) > 1 {
+		output.ETag = aws.String(`"replaced"`)
+	}
+
+	return output, err
+}
+
+func Test_Download(t *testing.T) {
+	client, server := mock.Client(t, sthree.WithDownload(sthree.DownloadConfig{PartSize: 1 << 10, Concurrency: 3}))
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	bucket := client.Bucket("assets")
+
+	body := bytes.Repeat([]byte("sthree"), 1000)
+	if _, err := bucket.Put("archive.bin", body); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if _, err := bucket.Put("empty.bin", []byte{}); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+
+	dir := t.TempDir()
+
+	t.Run("ranges are written to the file", func(t *testing.T) {
+		path := filepath.Join(dir, "archive.bin")
+		sent := len(server.Requests())
+
+		info, err := bucket.DownloadFile("archive.bin", path)
+		if err != nil {
+			t.Fatalf("failed to download object - %v", err.Error())
+		}
+
+		stored, _ := os.ReadFile(path)
+		if !bytes.Equal(stored, body) || info.Size != int64(len(body)) || info.ETag == "" {
+			t.Errorf("unexpected download - %d bytes written, %+v", len(stored), info)
+		}
+		if requests := len(server.Requests()) - sent; requests != 6 {
+			t.Errorf("expected 6 ranged requests, got %d", requests)
+		}
+	})
+
+	t.Run("empty objects are downloaded", func(t *testing.T) {
+		path := filepath.Join(dir, "empty.bin")
+
+		if _, err := bucket.DownloadFile("empty.bin", path); err != nil {
+			t.Fatalf("failed to download object - %v", err.Error())
+		}
+		if stat, err := os.Stat(path); err != nil || stat.Size() != 0 {
+			t.Errorf("expected empty file - %v", err)
+		}
+	})
+
+	t.Run("failed downloads leave no file behind", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.bin")
+
+		if _, err := bucket.DownloadFile("missing.bin", path); !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("expected not found error, got %v", err)
+		}
+
+		replaced := objects.Module{Bucket: "assets", Sdk: &replaced{API: client.Sdk}}
+		if _, err := replaced.DownloadFile("archive.bin", path, objects.Download{PartSize: 1 << 10}); !errors.Is(err, errs.ErrMismatch) {
+			t.Errorf("expected mismatch error, got %v", err)
+		}
+
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if name := entry.Name(); name != "archive.bin" && name != "empty.bin" {
+				t.Errorf("unexpected file left behind - %v", name)
+			}
+		}
+	})
+
+	t.Run("ranges are pinned to the version and ETag of the object", func(t *testing.T) {
+		recorded := &recorder{API: client.Sdk}
+		module := objects.Module{Bucket: "assets", Sdk: recorded}
+
+		var buf writerAt
+		info, err := module.Download("archive.bin", &buf, objects.Download{Get: objects.Get{Version: "v1"}, PartSize: 1 << 11, Concurrency: 1})
+		if err != nil {
+			t.Fatalf("failed to download object - %v", err.Error())
+		}
+		if !bytes.Equal(buf.data, body) {
+			t.Errorf("unexpected download - %d bytes written", len(buf.data))
+		}
+
+		if len(recorded.inputs) != 3 {
+			t.Fatalf("expected 3 ranged requests, got %d", len(recorded.inputs))
+		}
+		for i, input := range recorded.inputs {
+			if aws.StringValue(input.VersionId) != "v1" || input.Range == nil {
+				t.Errorf("unexpected input of range %d - %v", i, input)
+			}
+			if i > 0 && aws.StringValue(input.IfMatch) != info.ETag {
+				t.Errorf("expected range %d to be pinned to %v - %v", i, info.ETag, input)
+			}
+		}
+	})
+}
+
+// recorder records the inputs of the GetObject calls of a download.
+type recorder struct {
+	s3api.API
+	inputs []*s3.GetObjectInput
+}
+
+func (r *recorder) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	r.inputs = append(r.inputs, input)
+	return r.API.GetObjectWithContext(ctx, input, opts...)
+}
+
+// writerAt is an in-memory io.WriterAt, written by a single goroutine.
+type writerAt struct {
+	data []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+
+	return copy(w.data[off:], p), nil
+}
+
+func Test_Download_RateLimit(t *testing.T) {
+	client, _ := mock.Client(t, sthree.WithRateLimit(ratelimit.Config{
+		Read:  ratelimit.Limits{Global: ratelimit.Limit{Rate: 20, Burst: 1}},
+		Write: ratelimit.Limits{Global: ratelimit.Limit{Rate: 0.001, Burst: 2}},
+	}))
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	bucket := client.Bucket("assets")
+	if _, err := bucket.Put("archive.bin", bytes.Repeat([]byte("sthree"), 1000)); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+
+	// The write budget is spent, so the download must only use read tokens, one per range.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	var buf writerAt
+	if _, err := bucket.DownloadWithContext(ctx, "archive.bin", &buf, objects.Download{PartSize: 1 << 10, Concurrency: 1}); err != nil {
+		t.Fatalf("failed to download object - %v", err.Error())
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected each of the 6 ranges to wait for a read token, took %v", elapsed)
+	}
+}
@@ -1,8 +1,11 @@
 package bucket
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/service/s3"
 
+	"github.com/avila-r/sthree/pkg/middleware"
 	"github.com/avila-r/sthree/pkg/pointer"
 )
 
@@ -58,7 +61,23 @@ type CorsRule struct {
 // @param c The Cors configuration object containing the bucket name and rules.
 // @return An error if the CORS configuration cannot be applied, or nil if successful.
 func (m *Module) SetCors(c Cors) error {
-	_, err := m.Sdk.PutBucketCors(CorsInput(c))
+	return m.SetCorsWithContext(context.Background(), c)
+}
+
+// SetCorsWithContext is the same as SetCors, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param c The Cors configuration object containing the bucket name and rules.
+// @return An error if the CORS configuration cannot be applied, or nil if successful.
+func (m *Module) SetCorsWithContext(ctx context.Context, c Cors) error {
+	op := &middleware.Operation{
+		Name:   middleware.PutBucketCors,
+		Bucket: c.Bucket,
+		Input:  CorsInput(c),
+	}
+
+	_, err := middleware.Invoke(ctx, m.Chain, op, m.Sdk.PutBucketCorsWithContext)
 	return err
 }
 
@@ -1,9 +1,9 @@
 package bucket
 
 import (
-	"github.com/aws/aws-sdk-go/service/s3"
-
 	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/s3api"
 )
 
 // bucket.Module represents the S3 bucket configuration and provides methods for interacting with it.
@@ -13,8 +13,17 @@ type Module struct {
 	// Bucket is the name of the S3 bucket associated with this module.
 	Bucket string
 
-	// Sdk is the AWS S3 SDK client used to interact with the S3 bucket.
-	Sdk *s3.S3
+	// Sdk is the S3 API used to interact with the S3 bucket, usually an `*s3.S3`.
+	Sdk s3api.API
+
+	// Chain is the middleware chain every operation runs through.
+	Chain *middleware.Chain
+
+	// Upload tunes the multipart uploads of the objects module of the bucket.
+	Upload objects.UploadConfig
+
+	// Download tunes the ranged downloads of the objects module of the bucket.
+	Download objects.DownloadConfig
 }
 
 // Objects creates and returns a new `objects.Module` instance.
@@ -26,7 +35,10 @@ type Module struct {
 // @return A pointer to an `objects.Module` instance configured for the current S3 bucket.
 func (m *Module) Objects() *objects.Module {
 	return &objects.Module{
-		Bucket: m.Bucket,
-		Sdk:    m.Sdk,
+		Bucket:     m.Bucket,
+		Sdk:        m.Sdk,
+		Uploader:   objects.NewUploader(m.Sdk, m.Upload),
+		Downloader: m.Download,
+		Chain:      m.Chain,
 	}
 }
@@ -13,9 +13,14 @@ import (
 // @param name The name of the bucket to associate with the returned module.
 // @return An instance of `objects.Module` configured with the bucket name and the associated SDK.
 func (m *Module) Bucket(bucket string) *objects.Module {
+	sdk := m.sdkFor(bucket)
+
 	return &objects.Module{
-		Bucket: bucket,
-		Sdk:    m.Sdk,
+		Bucket:     bucket,
+		Sdk:        sdk,
+		Uploader:   objects.NewUploader(sdk, m.Upload),
+		Downloader: m.Download,
+		Chain:      m.Chain,
 	}
 }
 
@@ -50,7 +55,10 @@ func (m *Module) In(bucket string) *objects.Module {
 // @return An instance of `bucket.Module` configured with the bucket name and the associated SDK.
 func (m *Module) For(name string) *bucket.Module {
 	return &bucket.Module{
-		Bucket: name,
-		Sdk:    m.Sdk,
+		Bucket:   name,
+		Sdk:      m.sdkFor(name),
+		Chain:    m.Chain,
+		Upload:   m.Upload,
+		Download: m.Download,
 	}
 }
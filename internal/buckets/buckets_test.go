@@ -72,7 +72,7 @@ func Test_List(t *testing.T) {
 		t.Errorf("failed to list buckets - %v", err.Error())
 	}
 
-	for i, bucket := range output.Buckets {
-		t.Logf("[%v] Created Bucket: %v", i+1, *bucket.Name)
+	for i, bucket := range output {
+		t.Logf("[%v] Created Bucket: %v", i+1, bucket.Name)
 	}
 }
@@ -1,8 +1,12 @@
 package buckets
 
 import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 
+	"github.com/avila-r/sthree/pkg/middleware"
 	"github.com/avila-r/sthree/pkg/pointer"
 )
 
@@ -69,9 +73,21 @@ type Bucket struct {
 //
 // @param name The name of the bucket to create.
 // @param params Optional configuration parameters for the bucket.
-// @return A pointer to an s3.CreateBucketOutput containing details of the created bucket.
+// @return A pointer to the `BucketInfo` of the created bucket.
 // @return An error if the bucket creation fails.
-func (m *Module) Create(name string, params ...Bucket) (*s3.CreateBucketOutput, error) {
+func (m *Module) Create(name string, params ...Bucket) (*BucketInfo, error) {
+	return m.CreateWithContext(context.Background(), name, params...)
+}
+
+// CreateWithContext is the same as Create, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param name The name of the bucket to create.
+// @param params Optional configuration parameters for the bucket.
+// @return A pointer to the `BucketInfo` of the created bucket.
+// @return An error if the bucket creation fails.
+func (m *Module) CreateWithContext(ctx context.Context, name string, params ...Bucket) (*BucketInfo, error) {
 	input := &s3.CreateBucketInput{
 		Bucket: &name,
 	}
@@ -79,19 +95,41 @@ func (m *Module) Create(name string, params ...Bucket) (*s3.CreateBucketOutput,
 		input = fromNewParams(name, params[0])
 	}
 
-	return m.Sdk.CreateBucket(input)
+	op := &middleware.Operation{
+		Name:   middleware.CreateBucket,
+		Bucket: name,
+		Input:  input,
+	}
+
+	output, err := middleware.Invoke(ctx, m.Chain, op, m.Sdk.CreateBucketWithContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BucketInfo{Name: name, Location: aws.StringValue(output.Location)}, nil
 }
 
 // New is an alias for Create, providing an alternative method to create a new bucket.
 //
 // @param name The name of the bucket to create.
 // @param params Optional configuration parameters for the bucket.
-// @return A pointer to an s3.CreateBucketOutput containing details of the created bucket.
+// @return A pointer to the `BucketInfo` of the created bucket.
 // @return An error if the bucket creation fails.
-func (m *Module) New(name string, params ...Bucket) (*s3.CreateBucketOutput, error) {
+func (m *Module) New(name string, params ...Bucket) (*BucketInfo, error) {
 	return m.Create(name, params...)
 }
 
+// NewWithContext is an alias for CreateWithContext.
+//
+// @param ctx The context of the request.
+// @param name The name of the bucket to create.
+// @param params Optional configuration parameters for the bucket.
+// @return A pointer to the `BucketInfo` of the created bucket.
+// @return An error if the bucket creation fails.
+func (m *Module) NewWithContext(ctx context.Context, name string, params ...Bucket) (*BucketInfo, error) {
+	return m.CreateWithContext(ctx, name, params...)
+}
+
 // fromNewParams converts the provided Bucket configuration into an s3.CreateBucketInput.
 //
 // This function maps the custom Bucket fields to the appropriate AWS SDK structures,
@@ -1,20 +1,50 @@
 package buckets
 
-import "github.com/aws/aws-sdk-go/service/s3"
+import (
+	"context"
 
-func (m *Module) Delete(bucket string) (*s3.DeleteBucketOutput, error) {
-	input := &s3.DeleteBucketInput{
-		Bucket: &bucket,
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+func (m *Module) Delete(bucket string) (*DeletedBucket, error) {
+	return m.DeleteWithContext(context.Background(), bucket)
+}
+
+func (m *Module) DeleteWithContext(ctx context.Context, bucket string) (*DeletedBucket, error) {
+	op := &middleware.Operation{
+		Name:   middleware.DeleteBucket,
+		Bucket: bucket,
+		Input: &s3.DeleteBucketInput{
+			Bucket: &bucket,
+		},
+	}
+
+	if _, err := middleware.Invoke(ctx, m.Chain, op, m.sdkFor(bucket).DeleteBucketWithContext); err != nil {
+		return nil, err
 	}
 
-	return m.Sdk.DeleteBucket(input)
+	return &DeletedBucket{Name: bucket}, nil
+}
+
+func (m *Module) DeleteIfOwner(owner, bucket string) (*DeletedBucket, error) {
+	return m.DeleteIfOwnerWithContext(context.Background(), owner, bucket)
 }
 
-func (m *Module) DeleteIfOwner(owner, bucket string) (*s3.DeleteBucketOutput, error) {
-	input := &s3.DeleteBucketInput{
-		ExpectedBucketOwner: &owner,
-		Bucket:              &bucket,
+func (m *Module) DeleteIfOwnerWithContext(ctx context.Context, owner, bucket string) (*DeletedBucket, error) {
+	op := &middleware.Operation{
+		Name:   middleware.DeleteBucket,
+		Bucket: bucket,
+		Input: &s3.DeleteBucketInput{
+			ExpectedBucketOwner: &owner,
+			Bucket:              &bucket,
+		},
+	}
+
+	if _, err := middleware.Invoke(ctx, m.Chain, op, m.sdkFor(bucket).DeleteBucketWithContext); err != nil {
+		return nil, err
 	}
 
-	return m.Sdk.DeleteBucket(input)
+	return &DeletedBucket{Name: bucket}, nil
 }
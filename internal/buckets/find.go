@@ -1,16 +1,45 @@
 package buckets
 
-import "github.com/aws/aws-sdk-go/service/s3"
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree/pkg/middleware"
+)
 
 // List retrieves a list of all buckets owned by the sender.
 //
 // Required permissions:
 // - s3:ListAllMyBuckets
 //
-// @return A pointer to an s3.ListBucketsOutput containing details of all the buckets.
+// @return The `BucketInfo` of every bucket.
+// @return An error if the operation fails.
+func (m *Module) List() ([]BucketInfo, error) {
+	return m.ListWithContext(context.Background())
+}
+
+// ListWithContext is the same as List, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// Required permissions:
+// - s3:ListAllMyBuckets
+//
+// @param ctx The context of the request.
+// @return The `BucketInfo` of every bucket.
 // @return An error if the operation fails.
-func (m *Module) List() (*s3.ListBucketsOutput, error) {
-	return m.Sdk.ListBuckets(nil)
+func (m *Module) ListWithContext(ctx context.Context) ([]BucketInfo, error) {
+	op := &middleware.Operation{
+		Name:  middleware.ListBuckets,
+		Input: &s3.ListBucketsInput{},
+	}
+
+	output, err := middleware.Invoke(ctx, m.Chain, op, m.Sdk.ListBucketsWithContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBucketInfos(output), nil
 }
 
 // All is an alias for the List method, providing an alternative way to
@@ -19,8 +48,20 @@ func (m *Module) List() (*s3.ListBucketsOutput, error) {
 // Required permissions:
 // - s3:ListAllMyBuckets
 //
-// @return A pointer to an s3.ListBucketsOutput containing details of all the buckets.
+// @return The `BucketInfo` of every bucket.
 // @return An error if the operation fails.
-func (m *Module) All() (*s3.ListBucketsOutput, error) {
+func (m *Module) All() ([]BucketInfo, error) {
 	return m.List()
 }
+
+// AllWithContext is an alias for ListWithContext.
+//
+// Required permissions:
+// - s3:ListAllMyBuckets
+//
+// @param ctx The context of the request.
+// @return The `BucketInfo` of every bucket.
+// @return An error if the operation fails.
+func (m *Module) AllWithContext(ctx context.Context) ([]BucketInfo, error) {
+	return m.ListWithContext(ctx)
+}
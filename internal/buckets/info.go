@@ -0,0 +1,42 @@
+package buckets
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// BucketInfo describes a bucket owned by the sender.
+type BucketInfo struct {
+	// Name is the name of the bucket.
+	Name string
+
+	// CreationDate is when the bucket was created, if known.
+	CreationDate time.Time
+
+	// Location is the location of the bucket, returned by `Create` (e.g., "/assets").
+	Location string
+}
+
+// DeletedBucket describes a bucket deleted by `Delete`.
+type DeletedBucket struct {
+	// Name is the name of the deleted bucket.
+	Name string
+}
+
+// NewBucketInfos converts the output of a ListBuckets call into a list of `BucketInfo`.
+//
+// @param output The output of the call.
+// @return The buckets of the output, in order.
+func NewBucketInfos(output *s3.ListBucketsOutput) []BucketInfo {
+	buckets := []BucketInfo{}
+	for _, bucket := range output.Buckets {
+		buckets = append(buckets, BucketInfo{
+			Name:         aws.StringValue(bucket.Name),
+			CreationDate: aws.TimeValue(bucket.CreationDate),
+		})
+	}
+
+	return buckets
+}
@@ -1,14 +1,16 @@
 package buckets
 
 import (
-	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/s3api"
 )
 
 // buckets.Module serves as a wrapper for the AWS S3 SDK client and provides
 // methods to manage and interact with S3 buckets.
 //
 // Fields:
-// - Sdk (*s3.S3): An instance of the AWS S3 SDK client, used to
+// - Sdk (s3api.API): The S3 API implementation, usually the AWS S3 SDK client, used to
 //   communicate with the Amazon S3 service.
 //
 // Usage:
@@ -32,6 +34,28 @@ import (
 //       fmt.Println("Buckets:", buckets)
 //   }
 type Module struct {
-	// Sdk is the AWS S3 SDK client used to interact with the S3 service.
-	Sdk *s3.S3
+	// Sdk is the S3 API used to interact with the S3 service, usually an `*s3.S3`.
+	Sdk s3api.API
+
+	// Router optionally selects the SDK client serving a given bucket (e.g., per-region clients).
+	// When nil, Sdk is used for every bucket.
+	Router func(bucket string) s3api.API
+
+	// Chain is the middleware chain every operation runs through.
+	Chain *middleware.Chain
+
+	// Upload tunes the multipart uploads of the object modules derived from this module.
+	Upload objects.UploadConfig
+
+	// Download tunes the ranged downloads of the object modules derived from this module.
+	Download objects.DownloadConfig
+}
+
+// sdkFor returns the SDK client that should serve requests for the given bucket.
+func (m *Module) sdkFor(bucket string) s3api.API {
+	if m.Router == nil {
+		return m.Sdk
+	}
+
+	return m.Router(bucket)
 }
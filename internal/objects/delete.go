@@ -1,5 +1,7 @@
 package objects
 
+import "github.com/avila-r/sthree/pkg/retry"
+
 type Delete struct {
 	// The name of the bucket containing the object.
 	Bucket string
@@ -25,4 +27,7 @@ type Delete struct {
 
 	// Version ID used to reference a specific version of the object.
 	Version string
+
+	// Retry overrides the retry policy of the client for this request.
+	Retry *retry.Policy
 }
@@ -0,0 +1,327 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+// Defaults of the ranged downloads of `Download`.
+const (
+	DefaultDownloadPartSize    int64 = 5 << 20
+	DefaultDownloadConcurrency       = 5
+)
+
+// DownloadConfig tunes the ranged downloads of `Download`. Zero fields use the defaults.
+type DownloadConfig struct {
+	// PartSize is the size of each byte range fetched, in bytes; defaults to `DefaultDownloadPartSize` (5 MiB).
+	PartSize int64 `yaml:"part_size"`
+
+	// Concurrency is the number of ranges of a download fetched in parallel; defaults to
+	// `DefaultDownloadConcurrency`. The writer must accept concurrent writes at different offsets.
+	Concurrency int `yaml:"concurrency"`
+}
+
+// Download represents the parameters of a ranged download. The `Range` and `PartNumber` of
+// `Get` are ignored, since the whole object is downloaded, while its version, SSE-C key and
+// conditions apply to every range.
+type Download struct {
+	Get
+
+	// PartSize overrides the part size of the module for this download.
+	PartSize int64
+	// Concurrency overrides the concurrency of the module for this download.
+	Concurrency int
+}
+
+// Download downloads an object into w, fetching byte ranges of it concurrently.
+// The ranges after the first one are pinned to the ETag and version of the first response,
+// and the size and ETag of every range are verified, so an object that changes during the
+// download fails it instead of being written mixed.
+//
+// The download runs through the chain as a single `Download` operation, and each range as a
+// `GetObject` operation of its own, so rate limits and circuits account for every request sent.
+//
+// @param key The key of the object to download.
+// @param w The writer to write the object into, at the offsets of its ranges.
+// @param params Optional parameters for customizing the download (e.g., version, SSE-C key, part size).
+// @return A pointer to the `ObjectInfo` of the downloaded object, or an error.
+func (m *Module) Download(key string, w io.WriterAt, params ...Download) (*ObjectInfo, error) {
+	return m.DownloadWithContext(context.Background(), key, w, params...)
+}
+
+// DownloadWithContext is the same as Download, with the addition of a context
+// used to cancel the requests or apply a deadline to them.
+//
+// @param ctx The context of the requests.
+// @param key The key of the object to download.
+// @param w The writer to write the object into, at the offsets of its ranges.
+// @param params Optional parameters for customizing the download (e.g., version, SSE-C key, part size).
+// @return A pointer to the `ObjectInfo` of the downloaded object, or an error.
+func (m *Module) DownloadWithContext(ctx context.Context, key string, w io.WriterAt, params ...Download) (*ObjectInfo, error) {
+	cfg := Download{}
+	if len(params) > 0 {
+		cfg = params[0]
+	}
+
+	op := &middleware.Operation{
+		Name:   middleware.Download,
+		Bucket: m.Bucket,
+		Key:    key,
+		Input:  GetInput(m.Bucket, key, cfg.Get),
+	}
+	if cfg.Retry != nil {
+		op.Options = append(op.Options, cfg.Retry.Option())
+	}
+	// The ranged requests run through the chain themselves, so they only get the options of the
+	// caller, and not those the middlewares add to the download (e.g., its tracing).
+	options := op.Options
+
+	d := downloader{
+		sdk:         m.Sdk,
+		chain:       m.Chain,
+		bucket:      m.Bucket,
+		key:         key,
+		w:           w,
+		partSize:    pick(cfg.PartSize, m.Downloader.PartSize, DefaultDownloadPartSize),
+		concurrency: int(pick(int64(cfg.Concurrency), int64(m.Downloader.Concurrency), DefaultDownloadConcurrency)),
+	}
+
+	err := m.Chain.Do(ctx, op, func(ctx context.Context, op *middleware.Operation) error {
+		record, done := middleware.Composite(op)
+		defer done()
+
+		d.options = append([]request.Option{record}, options...)
+		info, err := d.download(ctx, op.Input.(*s3.GetObjectInput))
+		op.Output = info
+		return errs.Wrap(op.Name, op.Bucket, op.Key, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return middleware.Result[*ObjectInfo](op, nil)
+}
+
+// DownloadFile downloads an object into a file, concurrently (see `Download`). The object is
+// written to a temporary file in the directory of the path, renamed to the path once the
+// download is complete and verified, so the path never holds a partial object. The temporary
+// file is removed if the download fails. Files are created with the 0600 permissions.
+//
+// @param key The key of the object to download.
+// @param path The path of the file to write the object to, replaced if it exists.
+// @param params Optional parameters for customizing the download (e.g., version, SSE-C key, part size).
+// @return A pointer to the `ObjectInfo` of the downloaded object, or an error.
+func (m *Module) DownloadFile(key string, path string, params ...Download) (*ObjectInfo, error) {
+	return m.DownloadFileWithContext(context.Background(), key, path, params...)
+}
+
+// DownloadFileWithContext is the same as DownloadFile, with the addition of a context
+// used to cancel the requests or apply a deadline to them.
+//
+// @param ctx The context of the requests.
+// @param key The key of the object to download.
+// @param path The path of the file to write the object to, replaced if it exists.
+// @param params Optional parameters for customizing the download (e.g., version, SSE-C key, part size).
+// @return A pointer to the `ObjectInfo` of the downloaded object, or an error.
+func (m *Module) DownloadFileWithContext(ctx context.Context, key string, path string, params ...Download) (info *ObjectInfo, err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.download")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	if info, err = m.DownloadWithContext(ctx, key, file, params...); err != nil {
+		return nil, err
+	}
+	if err = file.Sync(); err != nil {
+		return nil, err
+	}
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(file.Name(), path); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// downloader fetches the ranges of a single download.
+type downloader struct {
+	sdk         s3api.API
+	chain       *middleware.Chain
+	bucket      string
+	key         string
+	w           io.WriterAt
+	partSize    int64
+	concurrency int
+	options     []request.Option
+}
+
+func (d *downloader) download(ctx context.Context, input *s3.GetObjectInput) (*ObjectInfo, error) {
+	base := *input
+	base.PartNumber = nil
+
+	// The first range reveals the size of the object, along with the ETag and version the
+	// remaining ranges are pinned to.
+	first, err := d.get(ctx, base, 0, d.partSize)
+	if awsErr := awserr.Error(nil); errors.As(err, &awsErr) && awsErr.Code() == "InvalidRange" {
+		// Empty objects have no satisfiable range.
+		first, err = d.get(ctx, base, 0, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	object := NewObject(d.bucket, d.key, first)
+	if err := d.write(first, 0, aws.Int64Value(first.ContentLength), object.ETag); err != nil {
+		return nil, err
+	}
+	size, err := total(first)
+	if err != nil {
+		return nil, err
+	}
+	object.Size = size
+
+	if base.IfMatch == nil && object.ETag != "" {
+		base.IfMatch = aws.String(object.ETag)
+	}
+	if base.VersionId == nil && object.VersionID != "" {
+		base.VersionId = aws.String(object.VersionID)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ranges := make(chan int64)
+	var (
+		wg      sync.WaitGroup
+		once    sync.Once
+		failure error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			failure = err
+			cancel()
+		})
+	}
+
+	for i := 0; i < d.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range ranges {
+				length := min(d.partSize, size-start)
+				output, err := d.get(ctx, base, start, length)
+				if err == nil {
+					err = d.write(output, start, length, object.ETag)
+				}
+				if err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	for start := aws.Int64Value(first.ContentLength); start < size && ctx.Err() == nil; start += d.partSize {
+		ranges <- start
+	}
+	close(ranges)
+	wg.Wait()
+
+	if failure != nil {
+		return nil, failure
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &object.ObjectInfo, nil
+}
+
+// get fetches length bytes of the object from start, or the whole object if length is zero,
+// through the chain.
+func (d *downloader) get(ctx context.Context, input s3.GetObjectInput, start, length int64) (*s3.GetObjectOutput, error) {
+	input.Range = nil
+	if length > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", start, start+length-1))
+	}
+
+	op := &middleware.Operation{
+		Name:    middleware.GetObject,
+		Bucket:  d.bucket,
+		Key:     d.key,
+		Input:   &input,
+		Options: d.options[:len(d.options):len(d.options)],
+	}
+
+	return middleware.Invoke(ctx, d.chain, op, d.sdk.GetObjectWithContext)
+}
+
+// write copies the body of a range into the writer at its offset, verifying its length and ETag.
+func (d *downloader) write(output *s3.GetObjectOutput, start, length int64, etag string) error {
+	defer output.Body.Close()
+
+	if tag := aws.StringValue(output.ETag); tag != etag {
+		return fmt.Errorf("range at %d has ETag %s, expected %s: %w", start, tag, etag, errs.ErrMismatch)
+	}
+
+	n, err := io.Copy(io.NewOffsetWriter(d.w, start), output.Body)
+	if err != nil {
+		return err
+	}
+	if n != length {
+		return fmt.Errorf("range at %d has %d bytes, expected %d: %w", start, n, length, errs.ErrMismatch)
+	}
+
+	return nil
+}
+
+// total returns the size of an object from the first range fetched of it.
+func total(output *s3.GetObjectOutput) (int64, error) {
+	contentRange := aws.StringValue(output.ContentRange)
+	if contentRange == "" {
+		// The whole object was returned.
+		return aws.Int64Value(output.ContentLength), nil
+	}
+
+	_, size, _ := strings.Cut(contentRange, "/")
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected content range %q", contentRange)
+	}
+
+	return n, nil
+}
+
+// pick returns the first positive value.
+func pick(values ...int64) int64 {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+
+	return 0
+}
@@ -2,6 +2,8 @@ package objects
 
 import (
 	"time"
+
+	"github.com/avila-r/sthree/pkg/retry"
 )
 
 type Get struct {
@@ -76,4 +78,7 @@ type Get struct {
 
 	// Version ID used to reference a specific version of the object.
 	Version string
+
+	// Retry overrides the retry policy of the client for this request.
+	Retry *retry.Policy
 }
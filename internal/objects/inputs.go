@@ -4,6 +4,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 
+	"github.com/avila-r/sthree/pkg/codec"
 	"github.com/avila-r/sthree/pkg/pointer"
 )
 
@@ -99,7 +100,9 @@ func ListInput(bucket string, params ...List) *s3.ListObjectsV2Input {
 // PutInput constructs an s3.PutObjectInput for uploading an object to S3.
 //
 // This method accepts a bucket name, object body, and optional configuration parameters.
-// If no parameters are provided, default values are used.
+// If no parameters are provided, default values are used. A body that cannot be read or
+// encoded fails the request with its error once the request is built; use `EncodePutInput`
+// to get the error right away, or to spool large readers to a temporary file.
 //
 // @param bucket The name of the S3 bucket to upload the object to.
 // @param body The body of the object to upload.
@@ -114,7 +117,7 @@ func PutInput(bucket, key string, body interface{}, params ...Put) *s3.PutObject
 	input := &s3.PutObjectInput{
 		Bucket:           pointer.NotBlank(bucket),
 		ACL:              pointer.NotBlank(cfg.Config.ACL),
-		Body:             ToReadSeeker(body),
+		Body:             readSeeker(body),
 		BucketKeyEnabled: pointer.NotFalse(cfg.Config.BucketKeyEnabled),
 		CacheControl:     pointer.NotBlank(cfg.Config.CacheControl),
 
@@ -167,6 +170,41 @@ func PutInput(bucket, key string, body interface{}, params ...Put) *s3.PutObject
 	return input
 }
 
+// EncodePutInput constructs an s3.PutObjectInput like `PutInput`, but converts the body with `Body`:
+// raw payloads are streamed unchanged, and structured values are encoded with the codec, whose content
+// type is used unless the parameters set one. Conversion errors are reported.
+//
+// @param bucket The name of the S3 bucket to upload the object to.
+// @param key The key of the object.
+// @param body The raw payload or the value to encode as the body of the object.
+// @param c The codec to encode structured values with; JSON when nil.
+// @param params Optional configuration parameters for the upload.
+// @return A pointer to an s3.PutObjectInput with the configured values, a function releasing
+// the spooled body once the object is uploaded, or the conversion error.
+func EncodePutInput(bucket, key string, body interface{}, c codec.Codec, params ...Put) (*s3.PutObjectInput, func(), error) {
+	if c == nil {
+		c = codec.JSON
+	}
+
+	reader, encoded, release, err := Body(body, c)
+	if err != nil {
+		return nil, release, err
+	}
+
+	cfg := Put{}
+	if len(params) > 0 {
+		cfg = params[0]
+	}
+	if encoded && cfg.Config.ContentType == "" {
+		cfg.Config.ContentType = c.ContentType()
+	}
+
+	input := PutInput(bucket, key, nil, cfg)
+	input.Body = reader
+
+	return input, release, nil
+}
+
 // DeleteInput constructs an s3.DeleteObjectInput for deleting an object from S3.
 //
 // This method accepts a bucket name, object key, and optional configuration parameters.
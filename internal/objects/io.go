@@ -4,23 +4,51 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"os"
+	"strings"
+
+	"github.com/avila-r/sthree/pkg/codec"
 )
 
 // Upload represents an object for uploading data to S3 with the provided readable body and object details.
 type Upload struct {
 	// Body holds the readable body payload to send to S3.
 	Body io.Reader
+	// Size is the expected size of a non-seekable body, if known. It scales the part size up
+	// for objects that would need more than 10,000 parts; the size of seekable bodies is measured.
+	Size int64
 	// ObjectDetails holds additional details of the object being uploaded.
 	ObjectDetails
 }
 
+// MaxMemorySpool is the size up to which non-seekable bodies are spooled to memory before
+// being sent. Larger bodies are spooled to a temporary file, removed once the upload completes.
+const MaxMemorySpool = 8 << 20
+
 // ToReadSeeker converts a given object into an io.ReadSeeker, suitable for uploading to S3.
-// It marshals the object into JSON and returns a ReadSeeker for the resulting byte array.
-// If the object cannot be marshaled, it returns an empty byte reader.
+// Raw payloads (`[]byte`, `string` and `io.Reader` values) are returned unchanged, non-seekable
+// readers being read into memory. Any other object is marshaled into JSON.
+// If the object cannot be marshaled or read, it returns an empty byte reader.
+//
+// Deprecated: ToReadSeeker hides read and marshal errors behind an empty body. Use `Body`,
+// which reports them.
 //
 // @param t The object to be converted into a ReadSeeker.
-// @return An io.ReadSeeker containing the marshaled JSON of the object, or an empty ReadSeeker in case of error.
+// @return An io.ReadSeeker containing the raw payload or the marshaled JSON of the object, or an empty ReadSeeker in case of error.
 func ToReadSeeker(t any) io.ReadSeeker {
+	if body, ok := raw(t); ok {
+		return body
+	}
+
+	if reader, ok := t.(io.Reader); ok {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return bytes.NewReader([]byte{})
+		}
+
+		return bytes.NewReader(data)
+	}
+
 	// Marshal the object into JSON
 	json, err := json.Marshal(t)
 	if err != nil {
@@ -31,3 +59,131 @@ func ToReadSeeker(t any) io.ReadSeeker {
 	// Return the ReadSeeker for the JSON byte array
 	return bytes.NewReader(json)
 }
+
+// Body converts the body of a put into an io.ReadSeeker. Raw payloads (`[]byte`, `string` and
+// `io.Reader` values, such as an `*os.File`) are streamed unchanged, non-seekable readers being
+// spooled to memory, or to a temporary file past `MaxMemorySpool`. Any other value is encoded with the codec.
+//
+// @param body The body of the put.
+// @param c The codec to encode structured values with; JSON when nil.
+// @return The body, whether it was encoded with the codec, a function releasing the spooled body, or an error.
+func Body(body any, c codec.Codec) (io.ReadSeeker, bool, func(), error) {
+	release := func() {}
+
+	if seeker, ok := raw(body); ok {
+		return seeker, false, release, nil
+	}
+
+	if reader, ok := body.(io.Reader); ok {
+		seeker, release, err := spool(reader)
+		return seeker, false, release, err
+	}
+
+	if c == nil {
+		c = codec.JSON
+	}
+
+	data, err := c.Marshal(body)
+	if err != nil {
+		return nil, false, release, err
+	}
+
+	return bytes.NewReader(data), true, release, nil
+}
+
+// readSeeker converts a body like `Body` with the JSON codec, reading non-seekable readers into
+// memory since the caller cannot release a spooled body. A body that cannot be read or encoded
+// is converted into one failing with the error, so that the request fails once it is built.
+func readSeeker(body any) io.ReadSeeker {
+	if reader, ok := body.(io.Reader); ok {
+		if seeker, ok := raw(body); ok {
+			return seeker
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return failed{err}
+		}
+
+		return bytes.NewReader(data)
+	}
+
+	seeker, _, _, err := Body(body, codec.JSON)
+	if err != nil {
+		return failed{err}
+	}
+
+	return seeker
+}
+
+// failed is a body failing every read and seek with the error of its conversion.
+type failed struct {
+	err error
+}
+
+func (f failed) Read([]byte) (int, error) {
+	return 0, f.err
+}
+
+func (f failed) Seek(int64, int) (int64, error) {
+	return 0, f.err
+}
+
+// Spools reports whether `Body` spools the body before it can be sent, because it is a reader
+// that cannot seek.
+//
+// @param body The body of a put.
+// @return Whether the body is a non-seekable reader.
+func Spools(body any) bool {
+	if _, ok := raw(body); ok {
+		return false
+	}
+
+	_, ok := body.(io.Reader)
+	return ok
+}
+
+// raw returns the payloads that are already seekable.
+func raw(body any) (io.ReadSeeker, bool) {
+	switch body := body.(type) {
+	case []byte:
+		return bytes.NewReader(body), true
+	case string:
+		return strings.NewReader(body), true
+	case io.ReadSeeker:
+		return body, true
+	}
+
+	return nil, false
+}
+
+// spool copies a reader into memory, or into a temporary file once it exceeds `MaxMemorySpool`.
+func spool(reader io.Reader) (io.ReadSeeker, func(), error) {
+	head, err := io.ReadAll(io.LimitReader(reader, MaxMemorySpool+1))
+	if err != nil {
+		return nil, func() {}, err
+	}
+	if len(head) <= MaxMemorySpool {
+		return bytes.NewReader(head), func() {}, nil
+	}
+
+	file, err := os.CreateTemp("", "sthree-spool-*")
+	if err != nil {
+		return nil, func() {}, err
+	}
+	release := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	if _, err := io.Copy(file, io.MultiReader(bytes.NewReader(head), reader)); err != nil {
+		release()
+		return nil, func() {}, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		release()
+		return nil, func() {}, err
+	}
+
+	return file, release, nil
+}
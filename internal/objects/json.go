@@ -0,0 +1,88 @@
+package objects
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/avila-r/sthree/pkg/codec"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// ContentTypeJSON is the content type of the objects stored by `PutJSON`.
+const ContentTypeJSON = "application/json"
+
+// PutJSON stores a value as a JSON object, with the `application/json` content type
+// unless the parameters set another one.
+//
+// @param m The module of the bucket to store the object in.
+// @param key The key of the object.
+// @param value The value to marshal.
+// @param params Optional parameters for customizing the upload (e.g., metadata, ACL).
+// @return A pointer to the `ObjectInfo` of the stored object, with its ETag and version, or an error.
+func PutJSON[T any](m *Module, key string, value T, params ...Put) (*ObjectInfo, error) {
+	return PutJSONWithContext(context.Background(), m, key, value, params...)
+}
+
+// PutJSONWithContext is the same as PutJSON, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param m The module of the bucket to store the object in.
+// @param key The key of the object.
+// @param value The value to marshal.
+// @param params Optional parameters for customizing the upload (e.g., metadata, ACL).
+// @return A pointer to the `ObjectInfo` of the stored object, with its ETag and version, or an error.
+func PutJSONWithContext[T any](ctx context.Context, m *Module, key string, value T, params ...Put) (*ObjectInfo, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errs.Wrap(middleware.PutObject, m.Bucket, key, err)
+	}
+
+	cfg := Put{}
+	if len(params) > 0 {
+		cfg = params[0]
+	}
+	if cfg.Config.ContentType == "" {
+		cfg.Config.ContentType = ContentTypeJSON
+	}
+
+	// A raw message is passed through as is by the JSON codec, whatever the codec of the module.
+	cfg.Codec = codec.JSON
+	return m.PutWithContext(ctx, key, json.RawMessage(data), cfg)
+}
+
+// GetJSON retrieves a JSON object and decodes it into a value of type T.
+// The body of the object is always closed.
+//
+// @param m The module of the bucket to retrieve the object from.
+// @param key The key of the object.
+// @param params Optional additional parameters for customizing the request (e.g., version).
+// @return The decoded value, the `ObjectInfo` of the object, with its ETag and version, or an error.
+func GetJSON[T any](m *Module, key string, params ...Get) (T, *ObjectInfo, error) {
+	return GetJSONWithContext[T](context.Background(), m, key, params...)
+}
+
+// GetJSONWithContext is the same as GetJSON, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param m The module of the bucket to retrieve the object from.
+// @param key The key of the object.
+// @param params Optional additional parameters for customizing the request (e.g., version).
+// @return The decoded value, the `ObjectInfo` of the object, with its ETag and version, or an error.
+func GetJSONWithContext[T any](ctx context.Context, m *Module, key string, params ...Get) (T, *ObjectInfo, error) {
+	var value T
+
+	object, err := m.GetWithContext(ctx, key, params...)
+	if err != nil {
+		return value, nil, err
+	}
+	defer object.Body.Close()
+
+	if err := json.NewDecoder(object.Body).Decode(&value); err != nil {
+		return value, &object.ObjectInfo, errs.Wrap(middleware.GetObject, m.Bucket, key, err)
+	}
+
+	return value, &object.ObjectInfo, nil
+}
@@ -1,5 +1,7 @@
 package objects
 
+import "github.com/avila-r/sthree/pkg/retry"
+
 type List struct {
 	// The name of the bucket containing the object.
 	Bucket string
@@ -48,4 +50,7 @@ type List struct {
 	// StartAfter is where you want
 	// Amazon S3 to start listing from.
 	StartAfter string
+
+	// Retry overrides the retry policy of the client for this request.
+	Retry *retry.Policy
 }
@@ -1,8 +1,20 @@
 package objects
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/avila-r/sthree/pkg/codec"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/s3api"
 )
 
 // objects.Module represents a service for interacting with an S3 bucket,
@@ -10,10 +22,18 @@ import (
 type Module struct {
 	// Bucket is the name of the S3 bucket.
 	Bucket string
-	// Sdk is the AWS SDK client for interacting with S3.
-	Sdk *s3.S3
-	// Uploader is used to upload objects to S3.
+	// Sdk is the S3 API for interacting with S3, usually an `*s3.S3`.
+	Sdk s3api.API
+	// Uploader is used to upload objects to S3 in multiple parts (see `NewUploader`).
+	// When it has no client, Upload uses Sdk, or the client it resolves (see `s3api.Resolver`),
+	// if it implements `s3iface.S3API`, and fails otherwise.
 	Uploader s3manager.Uploader
+	// Downloader tunes the ranged downloads of `Download`; zero fields use the defaults.
+	Downloader DownloadConfig
+	// Chain is the middleware chain every operation runs through.
+	Chain *middleware.Chain
+	// Codec encodes the bodies of `Put`, unless overridden per call; JSON when nil.
+	Codec codec.Codec
 }
 
 // Get retrieves an object from the S3 bucket by key.
@@ -21,11 +41,35 @@ type Module struct {
 //
 // @param key The key (filename) of the object to retrieve from S3.
 // @param params Optional additional parameters for customizing the request (e.g., range, version).
-// @return A pointer to the GetObjectOutput containing the retrieved object, or an error if the operation fails.
-func (m *Module) Get(key string, params ...Get) (*s3.GetObjectOutput, error) {
-	input := GetInput(m.Bucket, key, params...)
+// @return A pointer to the `Object` containing the retrieved object, or an error if the operation fails.
+func (m *Module) Get(key string, params ...Get) (*Object, error) {
+	return m.GetWithContext(context.Background(), key, params...)
+}
+
+// GetWithContext is the same as Get, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param key The key (filename) of the object to retrieve from S3.
+// @param params Optional additional parameters for customizing the request (e.g., range, version).
+// @return A pointer to the `Object` containing the retrieved object, or an error if the operation fails.
+func (m *Module) GetWithContext(ctx context.Context, key string, params ...Get) (*Object, error) {
+	op := &middleware.Operation{
+		Name:   middleware.GetObject,
+		Bucket: m.Bucket,
+		Key:    key,
+		Input:  GetInput(m.Bucket, key, params...),
+	}
+	if len(params) > 0 && params[0].Retry != nil {
+		op.Options = append(op.Options, params[0].Retry.Option())
+	}
+
+	output, err := middleware.Invoke(ctx, m.Chain, op, m.Sdk.GetObjectWithContext)
+	if err != nil {
+		return nil, err
+	}
 
-	return m.Sdk.GetObject(input)
+	return NewObject(m.Bucket, key, output), nil
 }
 
 // Delete deletes an object from the S3 bucket by key.
@@ -33,62 +77,238 @@ func (m *Module) Get(key string, params ...Get) (*s3.GetObjectOutput, error) {
 //
 // @param key The key (filename) of the object to delete from S3.
 // @param params Optional additional parameters for customizing the request (e.g., version).
-// @return A pointer to the DeleteObjectOutput indicating the result of the deletion, or an error.
-func (m *Module) Delete(key string, params ...Delete) (*s3.DeleteObjectOutput, error) {
-	input := DeleteInput(m.Bucket, key, params...)
+// @return A pointer to the `DeleteResult` of the deletion, or an error.
+func (m *Module) Delete(key string, params ...Delete) (*DeleteResult, error) {
+	return m.DeleteWithContext(context.Background(), key, params...)
+}
 
-	return m.Sdk.DeleteObject(input)
+// DeleteWithContext is the same as Delete, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param key The key (filename) of the object to delete from S3.
+// @param params Optional additional parameters for customizing the request (e.g., version).
+// @return A pointer to the `DeleteResult` of the deletion, or an error.
+func (m *Module) DeleteWithContext(ctx context.Context, key string, params ...Delete) (*DeleteResult, error) {
+	op := &middleware.Operation{
+		Name:   middleware.DeleteObject,
+		Bucket: m.Bucket,
+		Key:    key,
+		Input:  DeleteInput(m.Bucket, key, params...),
+	}
+	if len(params) > 0 && params[0].Retry != nil {
+		op.Options = append(op.Options, params[0].Retry.Option())
+	}
+
+	output, err := middleware.Invoke(ctx, m.Chain, op, m.Sdk.DeleteObjectWithContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDeleteResult(m.Bucket, key, output), nil
 }
 
 // ListObjects lists the objects in the S3 bucket.
 // It takes additional parameters for customizing the listing request.
 //
 // @param params Optional parameters for customizing the listing (e.g., prefix, delimiter, max keys).
-// @return A pointer to the ListObjectsV2Output containing the list of objects, or an error.
-func (m *Module) ListObjects(params ...List) (*s3.ListObjectsV2Output, error) {
-	input := ListInput(m.Bucket, params...)
+// @return A pointer to the `ListPage` containing the list of objects, or an error.
+func (m *Module) ListObjects(params ...List) (*ListPage, error) {
+	return m.ListObjectsWithContext(context.Background(), params...)
+}
+
+// ListObjectsWithContext is the same as ListObjects, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param params Optional parameters for customizing the listing (e.g., prefix, delimiter, max keys).
+// @return A pointer to the `ListPage` containing the list of objects, or an error.
+func (m *Module) ListObjectsWithContext(ctx context.Context, params ...List) (*ListPage, error) {
+	op := &middleware.Operation{
+		Name:   middleware.ListObjects,
+		Bucket: m.Bucket,
+		Input:  ListInput(m.Bucket, params...),
+	}
+	if len(params) > 0 && params[0].Retry != nil {
+		op.Options = append(op.Options, params[0].Retry.Option())
+	}
 
-	return m.Sdk.ListObjectsV2(input)
+	output, err := middleware.Invoke(ctx, m.Chain, op, m.Sdk.ListObjectsV2WithContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewListPage(m.Bucket, output), nil
 }
 
 // All is an alias for ListObjects to retrieve all objects in the S3 bucket.
 // It functions the same as ListObjects, providing an easy way to call the method.
 //
 // @param params Optional parameters for customizing the listing (e.g., prefix, delimiter, max keys).
-// @return A pointer to the ListObjectsV2Output containing the list of objects, or an error.
-func (m *Module) All(params ...List) (*s3.ListObjectsV2Output, error) {
+// @return A pointer to the `ListPage` containing the list of objects, or an error.
+func (m *Module) All(params ...List) (*ListPage, error) {
 	return m.ListObjects(params...)
 }
 
+// AllWithContext is an alias for ListObjectsWithContext.
+//
+// @param ctx The context of the request.
+// @param params Optional parameters for customizing the listing (e.g., prefix, delimiter, max keys).
+// @return A pointer to the `ListPage` containing the list of objects, or an error.
+func (m *Module) AllWithContext(ctx context.Context, params ...List) (*ListPage, error) {
+	return m.ListObjectsWithContext(ctx, params...)
+}
+
 // List is an alias for ListObjects to retrieve a list of objects from the S3 bucket.
 // It functions the same as ListObjects, providing an alternate name for the method.
 //
 // @param params Optional parameters for customizing the listing (e.g., prefix, delimiter, max keys).
-// @return A pointer to the ListObjectsV2Output containing the list of objects, or an error.
-func (m *Module) List(params ...List) (*s3.ListObjectsV2Output, error) {
+// @return A pointer to the `ListPage` containing the list of objects, or an error.
+func (m *Module) List(params ...List) (*ListPage, error) {
 	return m.ListObjects(params...)
 }
 
-// Upload uploads an object to the S3 bucket.
+// ListWithContext is an alias for ListObjectsWithContext.
+//
+// @param ctx The context of the request.
+// @param params Optional parameters for customizing the listing (e.g., prefix, delimiter, max keys).
+// @return A pointer to the `ListPage` containing the list of objects, or an error.
+func (m *Module) ListWithContext(ctx context.Context, params ...List) (*ListPage, error) {
+	return m.ListObjectsWithContext(ctx, params...)
+}
+
+// Upload uploads an object to the S3 bucket in multiple parts, sent concurrently.
 // It takes additional parameters for customizing the upload request.
 //
 // @param params Optional parameters for customizing the upload (e.g., content type, ACL).
-// @return A pointer to the UploadOutput indicating the result of the upload, or an error.
-func (m *Module) Upload(params ...Upload) (*s3manager.UploadOutput, error) {
+// @return A pointer to the `UploadResult` of the uploaded object, or an error.
+func (m *Module) Upload(params ...Upload) (*UploadResult, error) {
+	return m.UploadWithContext(context.Background(), params...)
+}
+
+// UploadWithContext is the same as Upload, with the addition of a context
+// used to cancel the upload, including every in-flight part, or apply a deadline to it.
+//
+// @param ctx The context of the upload.
+// @param params Optional parameters for customizing the upload (e.g., content type, ACL).
+// @return A pointer to the `UploadResult` of the uploaded object, or an error.
+func (m *Module) UploadWithContext(ctx context.Context, params ...Upload) (*UploadResult, error) {
 	input := UploadInput(m.Bucket, params...)
+	op := &middleware.Operation{
+		Name:   middleware.Upload,
+		Bucket: m.Bucket,
+		Key:    aws.StringValue(input.Key),
+		Input:  input,
+	}
+
+	size := int64(0)
+	if len(params) > 0 {
+		size = params[0].Size
+	}
+
+	var output *s3manager.UploadOutput
+	err := m.Chain.Do(ctx, op, func(ctx context.Context, op *middleware.Operation) (err error) {
+		uploader := m.Uploader
+		if uploader.S3 == nil {
+			api := m.Sdk
+			if resolver, ok := api.(s3api.Resolver); ok {
+				api = resolver.Resolve(ctx)
+			}
+			client, ok := api.(s3iface.S3API)
+			if !ok {
+				return errs.Wrap(op.Name, op.Bucket, op.Key, fmt.Errorf("multipart uploads require an API implementing s3iface.S3API: %w", errors.ErrUnsupported))
+			}
+			uploader.S3 = client
+		}
+
+		record, done := middleware.Composite(op)
+		defer done()
 
-	return m.Uploader.Upload(input)
+		options := append([]request.Option{record}, op.Options...)
+		output, err = uploader.UploadWithContext(ctx, op.Input.(*s3manager.UploadInput), s3manager.WithUploaderRequestOptions(options...), func(u *s3manager.Uploader) {
+			u.PartSize = PartSize(u.PartSize, size, u.MaxUploadParts)
+		})
+		op.Output = output
+		return errs.Wrap(op.Name, op.Bucket, op.Key, err)
+	})
+
+	output, err = middleware.Result[*s3manager.UploadOutput](op, err)
+	if err != nil {
+		return nil, err
+	}
+
+	input = op.Input.(*s3manager.UploadInput)
+	return &UploadResult{
+		ObjectInfo: ObjectInfo{
+			Bucket:      m.Bucket,
+			Key:         aws.StringValue(input.Key),
+			Size:        op.BytesSent,
+			ETag:        aws.StringValue(output.ETag),
+			VersionID:   aws.StringValue(output.VersionID),
+			ContentType: aws.StringValue(input.ContentType),
+			Metadata:    aws.StringValueMap(input.Metadata),
+		},
+		Location: output.Location,
+		UploadID: output.UploadID,
+	}, nil
 }
 
 // Put uploads an object to the S3 bucket using the PutObject API.
 // It takes the body of the object and additional parameters for customization.
+// Raw payloads (`[]byte`, `string` and `io.Reader` values) are uploaded unchanged, while any
+// other value is encoded with the codec of the call or module, JSON by default.
 //
 // @param key The key of the object to upload.
 // @param body The body of the object to upload.
 // @param params Optional parameters for customizing the upload (e.g., content type, ACL).
-// @return A pointer to the PutObjectOutput indicating the result of the upload, or an error.
-func (m *Module) Put(key string, body interface{}, params ...Put) (*s3.PutObjectOutput, error) {
-	input := PutInput(m.Bucket, key, body, params...)
+// @return A pointer to the `ObjectInfo` of the uploaded object, or an error.
+func (m *Module) Put(key string, body interface{}, params ...Put) (*ObjectInfo, error) {
+	return m.PutWithContext(context.Background(), key, body, params...)
+}
+
+// PutWithContext is the same as Put, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param key The key of the object to upload.
+// @param body The body of the object to upload.
+// @param params Optional parameters for customizing the upload (e.g., content type, ACL).
+// @return A pointer to the `ObjectInfo` of the uploaded object, or an error.
+func (m *Module) PutWithContext(ctx context.Context, key string, body interface{}, params ...Put) (*ObjectInfo, error) {
+	c := m.Codec
+	if len(params) > 0 && params[0].Codec != nil {
+		c = params[0].Codec
+	}
+
+	input, release, err := EncodePutInput(m.Bucket, key, body, c, params...)
+	if err != nil {
+		return nil, errs.Wrap(middleware.PutObject, m.Bucket, key, err)
+	}
+	defer release()
+
+	op := &middleware.Operation{
+		Name:   middleware.PutObject,
+		Bucket: m.Bucket,
+		Key:    key,
+		Input:  input,
+	}
+	if len(params) > 0 && params[0].Retry != nil {
+		op.Options = append(op.Options, params[0].Retry.Option())
+	}
+
+	output, err := middleware.Invoke(ctx, m.Chain, op, m.Sdk.PutObjectWithContext)
+	if err != nil {
+		return nil, err
+	}
 
-	return m.Sdk.PutObject(input)
+	input = op.Input.(*s3.PutObjectInput)
+	return &ObjectInfo{
+		Bucket:      m.Bucket,
+		Key:         key,
+		Size:        op.BytesSent,
+		ETag:        aws.StringValue(output.ETag),
+		VersionID:   aws.StringValue(output.VersionId),
+		ContentType: aws.StringValue(input.ContentType),
+		Metadata:    aws.StringValueMap(input.Metadata),
+	}, nil
 }
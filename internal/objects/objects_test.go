@@ -29,10 +29,11 @@ func Test_ObjectOperations(t *testing.T) {
 		output, err := client.In(bucket).List()
 		if err != nil {
 			t.Errorf("failed to retrieve objects in bucket to delete them - %v", err.Error())
+			return
 		}
 
-		for _, object := range output.Contents {
-			if _, err := client.In(bucket).Delete(*object.Key); err != nil {
+		for _, object := range output.Objects {
+			if _, err := client.In(bucket).Delete(object.Key); err != nil {
 				t.Errorf("failed to delete object - %v", err.Error())
 			}
 		}
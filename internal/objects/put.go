@@ -1,5 +1,10 @@
 package objects
 
+import (
+	"github.com/avila-r/sthree/pkg/codec"
+	"github.com/avila-r/sthree/pkg/retry"
+)
+
 // Put represents the parameters required to upload an object to an S3 bucket.
 // This struct is used in the PutObject API call to specify the object to be uploaded
 // and additional configuration settings.
@@ -18,11 +23,19 @@ type Put struct {
 	Key string
 
 	// Body contains the content of the object to be uploaded to S3.
-	// This can be any type that is serializable into an S3-compatible format.
+	// Raw payloads (`[]byte`, `string` and `io.Reader` values) are uploaded unchanged,
+	// and any other value is encoded with the codec.
 	Body interface{}
 
 	// Config contains additional details for uploading the object, such as metadata
 	// or access control settings. This field is of type ObjectDetails, which contains
 	// settings related to the object configuration.
 	Config ObjectDetails
+
+	// Retry overrides the retry policy of the client for this upload.
+	Retry *retry.Policy
+
+	// Codec encodes the body, overriding the codec of the module (JSON by default).
+	// Its content type is used unless `Config.ContentType` is set.
+	Codec codec.Codec
 }
@@ -0,0 +1,191 @@
+package objects
+
+import (
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree/pkg/codec"
+)
+
+// ObjectInfo describes an object stored in S3, without its content.
+type ObjectInfo struct {
+	// Bucket is the name of the bucket containing the object.
+	Bucket string
+
+	// Key is the key of the object.
+	Key string
+
+	// Size is the size of the object in bytes, if known.
+	Size int64
+
+	// ETag is the entity tag of the object, as returned by S3 (quotes included).
+	ETag string
+
+	// VersionID is the version of the object, if versioning is enabled on the bucket.
+	VersionID string
+
+	// LastModified is when the object was last modified, if known.
+	LastModified time.Time
+
+	// ContentType is the MIME type of the object, if known.
+	ContentType string
+
+	// StorageClass is the storage class of the object (e.g., "STANDARD"), if known.
+	StorageClass string
+
+	// Metadata holds the user-defined metadata of the object, if known.
+	Metadata map[string]string
+}
+
+// Object is an object retrieved from S3.
+type Object struct {
+	ObjectInfo
+
+	// Body is the content of the object. It must be closed by the caller.
+	Body io.ReadCloser
+}
+
+// DeleteResult describes an object deleted from S3.
+type DeleteResult struct {
+	// Bucket is the name of the bucket the object was deleted from.
+	Bucket string
+
+	// Key is the key of the deleted object.
+	Key string
+
+	// VersionID is the version deleted, or the version of the delete marker created
+	// if versioning is enabled on the bucket.
+	VersionID string
+
+	// DeleteMarker reports whether the deletion created or removed a delete marker.
+	DeleteMarker bool
+}
+
+// UploadResult describes an object uploaded to S3 in multiple parts.
+type UploadResult struct {
+	ObjectInfo
+
+	// Location is the URL of the uploaded object.
+	Location string
+
+	// UploadID is the ID of the multipart upload, or empty if the object was small enough
+	// to be uploaded in a single request.
+	UploadID string
+}
+
+// ListPage is a single page of a bucket listing.
+type ListPage struct {
+	// Bucket is the name of the listed bucket.
+	Bucket string
+
+	// Prefix is the prefix the listing was limited to, if any.
+	Prefix string
+
+	// Objects are the objects of the page.
+	Objects []ObjectInfo
+
+	// CommonPrefixes are the prefixes rolled up by the delimiter, if one was given.
+	CommonPrefixes []string
+
+	// IsTruncated reports whether more objects remain to be listed.
+	IsTruncated bool
+
+	// NextContinuationToken is the token to pass as `List.ContinuationToken` to list the next page.
+	NextContinuationToken string
+}
+
+// NewObject converts the output of a GetObject call into an `Object`.
+//
+// @param bucket The bucket the object was retrieved from.
+// @param key The key of the object.
+// @param output The output of the call.
+// @return A pointer to the `Object`.
+func NewObject(bucket, key string, output *s3.GetObjectOutput) *Object {
+	return &Object{
+		ObjectInfo: ObjectInfo{
+			Bucket:       bucket,
+			Key:          key,
+			Size:         aws.Int64Value(output.ContentLength),
+			ETag:         aws.StringValue(output.ETag),
+			VersionID:    aws.StringValue(output.VersionId),
+			LastModified: aws.TimeValue(output.LastModified),
+			ContentType:  aws.StringValue(output.ContentType),
+			StorageClass: aws.StringValue(output.StorageClass),
+			Metadata:     aws.StringValueMap(output.Metadata),
+		},
+		Body: output.Body,
+	}
+}
+
+// Decode reads the body of the object, closes it, and decodes it into the value pointed to by v,
+// with the codec registered for the content type of the object (see `codec.ForContentType`).
+// Objects of an unknown content type are decoded as JSON, the default codec of `Put`.
+//
+// @param v A pointer to the value to decode into.
+// @return An error if the body cannot be read or decoded.
+func (o *Object) Decode(v any) error {
+	defer o.Body.Close()
+
+	data, err := io.ReadAll(o.Body)
+	if err != nil {
+		return err
+	}
+
+	c, ok := codec.ForContentType(o.ContentType)
+	if !ok {
+		c = codec.JSON
+	}
+
+	return c.Unmarshal(data, v)
+}
+
+// NewDeleteResult converts the output of a DeleteObject call into a `DeleteResult`.
+//
+// @param bucket The bucket the object was deleted from.
+// @param key The key of the object.
+// @param output The output of the call.
+// @return A pointer to the `DeleteResult`.
+func NewDeleteResult(bucket, key string, output *s3.DeleteObjectOutput) *DeleteResult {
+	return &DeleteResult{
+		Bucket:       bucket,
+		Key:          key,
+		VersionID:    aws.StringValue(output.VersionId),
+		DeleteMarker: aws.BoolValue(output.DeleteMarker),
+	}
+}
+
+// NewListPage converts the output of a ListObjectsV2 call into a `ListPage`.
+//
+// @param bucket The listed bucket.
+// @param output The output of the call.
+// @return A pointer to the `ListPage`.
+func NewListPage(bucket string, output *s3.ListObjectsV2Output) *ListPage {
+	page := &ListPage{
+		Bucket:                bucket,
+		Prefix:                aws.StringValue(output.Prefix),
+		Objects:               []ObjectInfo{},
+		CommonPrefixes:        []string{},
+		IsTruncated:           aws.BoolValue(output.IsTruncated),
+		NextContinuationToken: aws.StringValue(output.NextContinuationToken),
+	}
+
+	for _, object := range output.Contents {
+		page.Objects = append(page.Objects, ObjectInfo{
+			Bucket:       bucket,
+			Key:          aws.StringValue(object.Key),
+			Size:         aws.Int64Value(object.Size),
+			ETag:         aws.StringValue(object.ETag),
+			LastModified: aws.TimeValue(object.LastModified),
+			StorageClass: aws.StringValue(object.StorageClass),
+		})
+	}
+
+	for _, prefix := range output.CommonPrefixes {
+		page.CommonPrefixes = append(page.CommonPrefixes, aws.StringValue(prefix.Prefix))
+	}
+
+	return page
+}
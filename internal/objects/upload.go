@@ -0,0 +1,68 @@
+package objects
+
+import (
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+// UploadConfig tunes the multipart uploads of `Upload`. Zero fields use the defaults of `s3manager`.
+type UploadConfig struct {
+	// PartSize is the size of each part in bytes, at least `s3manager.MinUploadPartSize` (5 MiB),
+	// which is also the default. It is scaled up for objects that would need more than
+	// `s3manager.MaxUploadParts` (10,000) parts.
+	PartSize int64 `yaml:"part_size"`
+
+	// Concurrency is the number of parts of an upload sent in parallel; defaults to 5.
+	// Up to PartSize * Concurrency bytes of a non-seekable body are buffered in memory.
+	Concurrency int `yaml:"concurrency"`
+
+	// LeavePartsOnError keeps the parts of failed uploads instead of aborting them. Parts
+	// left behind are billed until the upload is completed or aborted.
+	LeavePartsOnError bool `yaml:"leave_parts_on_error"`
+}
+
+// NewUploader creates the multipart uploader of a module.
+//
+// Multipart uploads are built on the AWS SDK, so they require an API that implements
+// `s3iface.S3API`, such as the default `*s3.S3` client.
+//
+// @param api The S3 API of the module.
+// @param cfg The part size, concurrency and error handling of the uploads.
+// @return An `s3manager.Uploader`, without a client if the API does not implement `s3iface.S3API`.
+func NewUploader(api s3api.API, cfg UploadConfig) s3manager.Uploader {
+	uploader := s3manager.Uploader{
+		PartSize:          cfg.PartSize,
+		Concurrency:       cfg.Concurrency,
+		LeavePartsOnError: cfg.LeavePartsOnError,
+	}
+	if client, ok := api.(s3iface.S3API); ok {
+		uploader.S3 = client
+	}
+
+	return uploader
+}
+
+// PartSize scales a part size up so that an object of the given size fits in the maximum number of parts.
+// Zero part sizes and part counts stand for the defaults of `s3manager`.
+//
+// @param partSize The configured part size.
+// @param size The size of the object, or zero if unknown.
+// @param maxParts The maximum number of parts of an upload.
+// @return The part size to upload the object with.
+func PartSize(partSize, size int64, maxParts int) int64 {
+	if partSize <= 0 {
+		partSize = s3manager.DefaultUploadPartSize
+	}
+	if maxParts <= 0 {
+		maxParts = s3manager.MaxUploadParts
+	}
+
+	if size <= 0 || size/partSize < int64(maxParts) {
+		return partSize
+	}
+
+	// Add one byte to account for the remainder of the division.
+	return size/int64(maxParts) + 1
+}
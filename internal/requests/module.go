@@ -1,11 +1,15 @@
 package requests
 
 import (
-	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/s3api"
 )
 
 // Module is a wrapper around the AWS S3 SDK client, allowing operations
 // to be performed using the AWS SDK methods for S3 services.
 type Module struct {
-	Sdk *s3.S3 // The S3 client to interact with S3 services.
+	Sdk s3api.Requester // The S3 client to interact with S3 services, usually an `*s3.S3`.
+
+	// Chain is the middleware chain run when the returned requests are sent.
+	Chain *middleware.Chain
 }
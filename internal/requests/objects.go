@@ -1,10 +1,14 @@
 package requests
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 
 	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
 )
 
 // GetObject initiates an S3 GetObject request using the provided 'Get' object from the 'objects' package.
@@ -20,7 +24,18 @@ import (
 func (m *Module) GetObject(from objects.Get) (*request.Request, *s3.GetObjectOutput) {
 	input := objects.GetInput(from.Bucket, from.Key, from)
 
-	return m.Sdk.GetObjectRequest(input)
+	req, output := m.Sdk.GetObjectRequest(input)
+	op := &middleware.Operation{
+		Name:   middleware.GetObject,
+		Bucket: from.Bucket,
+		Key:    from.Key,
+	}
+	if from.Retry != nil {
+		op.Options = append(op.Options, from.Retry.Option())
+	}
+	middleware.Attach(req, m.Chain, op)
+
+	return req, output
 }
 
 // DeleteObject initiates an S3 DeleteObject request using the provided 'Delete' object from the 'objects' package.
@@ -36,7 +51,18 @@ func (m *Module) GetObject(from objects.Get) (*request.Request, *s3.GetObjectOut
 func (m *Module) DeleteObject(from objects.Delete) (*request.Request, *s3.DeleteObjectOutput) {
 	input := objects.DeleteInput(from.Bucket, from.Key, from)
 
-	return m.Sdk.DeleteObjectRequest(input)
+	req, output := m.Sdk.DeleteObjectRequest(input)
+	op := &middleware.Operation{
+		Name:   middleware.DeleteObject,
+		Bucket: from.Bucket,
+		Key:    from.Key,
+	}
+	if from.Retry != nil {
+		op.Options = append(op.Options, from.Retry.Option())
+	}
+	middleware.Attach(req, m.Chain, op)
+
+	return req, output
 }
 
 // ListObjects initiates an S3 ListObjectsV2 request using the provided 'List' object from the 'objects' package.
@@ -52,7 +78,17 @@ func (m *Module) DeleteObject(from objects.Delete) (*request.Request, *s3.Delete
 func (m *Module) ListObjects(from objects.List) (*request.Request, *s3.ListObjectsV2Output) {
 	input := objects.ListInput(from.Bucket, from)
 
-	return m.Sdk.ListObjectsV2Request(input)
+	req, output := m.Sdk.ListObjectsV2Request(input)
+	op := &middleware.Operation{
+		Name:   middleware.ListObjects,
+		Bucket: from.Bucket,
+	}
+	if from.Retry != nil {
+		op.Options = append(op.Options, from.Retry.Option())
+	}
+	middleware.Attach(req, m.Chain, op)
+
+	return req, output
 }
 
 // PutObject initiates an S3 PutObject request using the provided 'Put' object from the 'objects' package.
@@ -66,7 +102,109 @@ func (m *Module) ListObjects(from objects.List) (*request.Request, *s3.ListObjec
 // - A request object for the S3 PutObject operation.
 // - A 'PutObjectOutput' containing the details of the uploaded object.
 func (m *Module) PutObject(from objects.Put) (*request.Request, *s3.PutObjectOutput) {
-	input := objects.PutInput(from.Bucket, from.Key, from.Body, from)
+	input := objects.PutInput(from.Bucket, from.Key, nil, from)
+	input.Body = nil
+
+	req, output := m.Sdk.PutObjectRequest(input)
+
+	// The body is converted when the request is built to be sent. Presigned requests carry no
+	// body, so readers that would be spooled are left unread; a body spooled to a temporary
+	// file is removed once the request completes.
+	release := func() {}
+	req.Handlers.Build.PushFront(func(r *request.Request) {
+		if input.Body != nil || (r.IsPresigned() && objects.Spools(from.Body)) {
+			return
+		}
+
+		encoded, free, err := objects.EncodePutInput(from.Bucket, from.Key, from.Body, from.Codec, from)
+		if err != nil {
+			r.Error = errs.Wrap(middleware.PutObject, from.Bucket, from.Key, err)
+			return
+		}
+		release = free
+		input.Body, input.ContentType = encoded.Body, encoded.ContentType
+	})
+	req.Handlers.Complete.PushBack(func(*request.Request) {
+		release()
+	})
+
+	op := &middleware.Operation{
+		Name:   middleware.PutObject,
+		Bucket: from.Bucket,
+		Key:    from.Key,
+	}
+	if from.Retry != nil {
+		op.Options = append(op.Options, from.Retry.Option())
+	}
+	middleware.Attach(req, m.Chain, op)
+
+	return req, output
+}
+
+// GetObjectWithContext is the same as GetObject, with the addition of a context that
+// is attached to the returned request and used to cancel it or apply a deadline to it.
+//
+// Parameters:
+//   - ctx: The context attached to the request.
+//   - from: An 'objects.Get' struct describing the operation.
+//
+// Returns:
+// - A request object bound to the given context.
+// - A 'GetObjectOutput' filled once the request is sent.
+func (m *Module) GetObjectWithContext(ctx context.Context, from objects.Get) (*request.Request, *s3.GetObjectOutput) {
+	req, output := m.GetObject(from)
+	req.SetContext(ctx)
+
+	return req, output
+}
+
+// DeleteObjectWithContext is the same as DeleteObject, with the addition of a context that
+// is attached to the returned request and used to cancel it or apply a deadline to it.
+//
+// Parameters:
+//   - ctx: The context attached to the request.
+//   - from: An 'objects.Delete' struct describing the operation.
+//
+// Returns:
+// - A request object bound to the given context.
+// - A 'DeleteObjectOutput' filled once the request is sent.
+func (m *Module) DeleteObjectWithContext(ctx context.Context, from objects.Delete) (*request.Request, *s3.DeleteObjectOutput) {
+	req, output := m.DeleteObject(from)
+	req.SetContext(ctx)
+
+	return req, output
+}
+
+// ListObjectsWithContext is the same as ListObjects, with the addition of a context that
+// is attached to the returned request and used to cancel it or apply a deadline to it.
+//
+// Parameters:
+//   - ctx: The context attached to the request.
+//   - from: An 'objects.List' struct describing the operation.
+//
+// Returns:
+// - A request object bound to the given context.
+// - A 'ListObjectsV2Output' filled once the request is sent.
+func (m *Module) ListObjectsWithContext(ctx context.Context, from objects.List) (*request.Request, *s3.ListObjectsV2Output) {
+	req, output := m.ListObjects(from)
+	req.SetContext(ctx)
+
+	return req, output
+}
+
+// PutObjectWithContext is the same as PutObject, with the addition of a context that
+// is attached to the returned request and used to cancel it or apply a deadline to it.
+//
+// Parameters:
+//   - ctx: The context attached to the request.
+//   - from: An 'objects.Put' struct describing the operation.
+//
+// Returns:
+// - A request object bound to the given context.
+// - A 'PutObjectOutput' filled once the request is sent.
+func (m *Module) PutObjectWithContext(ctx context.Context, from objects.Put) (*request.Request, *s3.PutObjectOutput) {
+	req, output := m.PutObject(from)
+	req.SetContext(ctx)
 
-	return m.Sdk.PutObjectRequest(input)
+	return req, output
 }
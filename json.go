@@ -0,0 +1,55 @@
+package sthree
+
+import (
+	"context"
+
+	"github.com/avila-r/sthree/internal/objects"
+)
+
+// PutJSON stores a value as a JSON object in the bucket of the module (e.g., `client.Bucket("assets")`),
+// with the `application/json` content type unless the parameters set another one.
+//
+// @param m The module of the bucket to store the object in.
+// @param key The key of the object.
+// @param value The value to marshal.
+// @param params Optional parameters for customizing the upload (e.g., metadata, ACL).
+// @return A pointer to the `ObjectInfo` of the stored object, with its ETag and version, or an error.
+func PutJSON[T any](m *objects.Module, key string, value T, params ...objects.Put) (*ObjectInfo, error) {
+	return objects.PutJSON(m, key, value, params...)
+}
+
+// PutJSONWithContext is the same as PutJSON, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param m The module of the bucket to store the object in.
+// @param key The key of the object.
+// @param value The value to marshal.
+// @param params Optional parameters for customizing the upload (e.g., metadata, ACL).
+// @return A pointer to the `ObjectInfo` of the stored object, with its ETag and version, or an error.
+func PutJSONWithContext[T any](ctx context.Context, m *objects.Module, key string, value T, params ...objects.Put) (*ObjectInfo, error) {
+	return objects.PutJSONWithContext(ctx, m, key, value, params...)
+}
+
+// GetJSON retrieves a JSON object from the bucket of the module and decodes it into a value of type T.
+// The body of the object is always closed.
+//
+// @param m The module of the bucket to retrieve the object from.
+// @param key The key of the object.
+// @param params Optional additional parameters for customizing the request (e.g., version).
+// @return The decoded value, the `ObjectInfo` of the object, with its ETag and version, or an error.
+func GetJSON[T any](m *objects.Module, key string, params ...objects.Get) (T, *ObjectInfo, error) {
+	return objects.GetJSON[T](m, key, params...)
+}
+
+// GetJSONWithContext is the same as GetJSON, with the addition of a context
+// used to cancel the request or apply a deadline to it.
+//
+// @param ctx The context of the request.
+// @param m The module of the bucket to retrieve the object from.
+// @param key The key of the object.
+// @param params Optional additional parameters for customizing the request (e.g., version).
+// @return The decoded value, the `ObjectInfo` of the object, with its ETag and version, or an error.
+func GetJSONWithContext[T any](ctx context.Context, m *objects.Module, key string, params ...objects.Get) (T, *ObjectInfo, error) {
+	return objects.GetJSONWithContext[T](ctx, m, key, params...)
+}
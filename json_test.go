@@ -0,0 +1,68 @@
+package sthree_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+type manifest struct {
+	Name    string   `json:"name"`
+	Version int      `json:"version"`
+	Files   []string `json:"files"`
+}
+
+func Test_JSON(t *testing.T) {
+	client, server := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	bucket := client.Bucket("assets")
+
+	expected := manifest{Name: "site", Version: 3, Files: []string{"index.html", "logo.png"}}
+	info, err := sthree.PutJSON(bucket, "manifest.json", expected)
+	if err != nil {
+		t.Fatalf("failed to put json - %v", err.Error())
+	}
+	if info.ETag == "" || info.ContentType != "application/json" {
+		t.Errorf("unexpected object info - %+v", info)
+	}
+
+	if body, _ := server.Object("assets", "manifest.json"); string(body) != `{"name":"site","version":3,"files":["index.html","logo.png"]}` {
+		t.Errorf("unexpected stored body - %s", body)
+	}
+
+	value, info, err := sthree.GetJSON[manifest](bucket, "manifest.json")
+	if err != nil {
+		t.Fatalf("failed to get json - %v", err.Error())
+	}
+	if value.Name != expected.Name || value.Version != expected.Version || len(value.Files) != 2 {
+		t.Errorf("unexpected value - %+v", value)
+	}
+	if info.ETag == "" || info.ContentType != "application/json" {
+		t.Errorf("unexpected object info - %+v", info)
+	}
+
+	if _, err := sthree.PutJSON(bucket, "invalid.json", math.Inf(1)); !errors.As(err, new(*errs.Error)) {
+		t.Errorf("expected marshal error, got %v", err)
+	}
+	if _, ok := server.Object("assets", "invalid.json"); ok {
+		t.Errorf("expected invalid value not to be stored")
+	}
+
+	if _, err := bucket.Put("plain.txt", "not an object"); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if _, _, err := sthree.GetJSON[manifest](bucket, "plain.txt"); err == nil {
+		t.Errorf("expected unmarshal error")
+	}
+
+	if _, _, err := sthree.GetJSON[manifest](bucket, "missing.json"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package sthree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by `Load`.
+const (
+	EnvConfigFile          = "STHREE_CONFIG_FILE"
+	EnvProfile             = "STHREE_PROFILE"
+	EnvRegion              = "STHREE_REGION"
+	EnvEndpoint            = "STHREE_ENDPOINT"
+	EnvForcePathStyle      = "STHREE_FORCE_PATH_STYLE"
+	EnvDisableSSL          = "STHREE_DISABLE_SSL"
+	EnvDiscoverRegions     = "STHREE_DISCOVER_REGIONS"
+	EnvAccessKeyID         = "STHREE_ACCESS_KEY_ID"
+	EnvSecretAccessKey     = "STHREE_SECRET_ACCESS_KEY"
+	EnvSessionToken        = "STHREE_SESSION_TOKEN"
+	EnvCredentialsFromEnv  = "STHREE_CREDENTIALS_FROM_ENV"
+	EnvMaxIdleConns        = "STHREE_MAX_IDLE_CONNS"
+	EnvMaxIdleConnsPerHost = "STHREE_MAX_IDLE_CONNS_PER_HOST"
+	EnvMaxConnsPerHost     = "STHREE_MAX_CONNS_PER_HOST"
+	EnvIdleConnTimeout     = "STHREE_IDLE_CONN_TIMEOUT"
+)
+
+// LoadOption customizes where `Load` reads the configuration from.
+type LoadOption func(*loader)
+
+// FromFile reads the configuration from a YAML or JSON file,
+// taking precedence over the `STHREE_CONFIG_FILE` environment variable.
+//
+// @param path The path of the configuration file.
+// @return A LoadOption that sets the configuration file.
+func FromFile(path string) LoadOption {
+	return func(l *loader) {
+		l.file = path
+	}
+}
+
+// FromProfile reads the region and credentials from the named AWS shared-config profile,
+// taking precedence over `STHREE_PROFILE` and the profile named in the configuration file.
+//
+// @param name The name of the profile.
+// @return A LoadOption that sets the profile.
+func FromProfile(name string) LoadOption {
+	return func(l *loader) {
+		l.profile = name
+	}
+}
+
+// WithoutEnv stops `Load` from reading `STHREE_*` environment variables.
+//
+// @return A LoadOption that disables the environment source.
+func WithoutEnv() LoadOption {
+	return func(l *loader) {
+		l.skipEnv = true
+	}
+}
+
+// Load builds a `Config` from a configuration file, `STHREE_*` environment variables
+// and an AWS shared-config profile.
+//
+// Sources are applied from lowest to highest precedence:
+//  1. The AWS shared-config profile, which only fills the region when no other source sets it.
+//  2. The YAML or JSON file given by `FromFile` or `STHREE_CONFIG_FILE`.
+//  3. The `STHREE_*` environment variables.
+//
+// Every problem found along the way is collected, so the returned error lists all invalid fields at once.
+//
+// @param opts Optional settings to choose the file, the profile or to ignore the environment.
+// @return The loaded `Config`, or a `*ConfigError` listing every invalid field.
+func Load(opts ...LoadOption) (Config, error) {
+	l := &loader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l.load()
+}
+
+// loader accumulates the configuration and the problems found while reading every source.
+type loader struct {
+	file     string
+	profile  string
+	skipEnv  bool
+	cfg      Config
+	problems []FieldError
+}
+
+func (l *loader) invalid(field, reason string) {
+	l.problems = append(l.problems, FieldError{Field: field, Reason: reason})
+}
+
+func (l *loader) load() (Config, error) {
+	file := l.file
+	if file == "" && !l.skipEnv {
+		file = os.Getenv(EnvConfigFile)
+	}
+	if file != "" {
+		l.readFile(file)
+	}
+
+	if !l.skipEnv {
+		l.readEnv()
+	}
+
+	if l.profile != "" {
+		l.cfg.Profile = l.profile
+	}
+	if l.cfg.Profile != "" {
+		l.readProfile(l.cfg.Profile)
+	}
+
+	if err := l.cfg.Validate(); err != nil {
+		var cfgErr *ConfigError
+		if errors.As(err, &cfgErr) {
+			l.problems = append(l.problems, cfgErr.Fields...)
+		}
+	}
+
+	if len(l.problems) > 0 {
+		return l.cfg, &ConfigError{Fields: l.problems}
+	}
+
+	return l.cfg, nil
+}
+
+// readFile decodes a YAML or JSON file into the configuration.
+// JSON documents are valid YAML, so both formats share the same decoder.
+func (l *loader) readFile(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		l.invalid("File", err.Error())
+		return
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&l.cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			l.invalid("File", err.Error())
+			return
+		}
+
+		for _, reason := range typeErr.Errors {
+			l.invalid("File", reason)
+		}
+	}
+}
+
+// readEnv overrides the configuration with every `STHREE_*` variable that is set.
+func (l *loader) readEnv() {
+	l.envString(EnvProfile, &l.cfg.Profile)
+	l.envString(EnvRegion, &l.cfg.Region)
+	l.envString(EnvEndpoint, &l.cfg.Endpoint)
+	l.envBool(EnvForcePathStyle, &l.cfg.ForcePathStyle)
+	l.envBool(EnvDisableSSL, &l.cfg.DisableSSL)
+	l.envBool(EnvDiscoverRegions, &l.cfg.DiscoverRegions)
+
+	l.envString(EnvAccessKeyID, &l.cfg.Credentials.AccessKeyID)
+	l.envString(EnvSecretAccessKey, &l.cfg.Credentials.SecretAccessKey)
+	l.envString(EnvSessionToken, &l.cfg.Credentials.SessionToken)
+	l.envBool(EnvCredentialsFromEnv, &l.cfg.Credentials.FromEnv)
+
+	l.envInt(EnvMaxIdleConns, &l.cfg.MaxIdleConns)
+	l.envInt(EnvMaxIdleConnsPerHost, &l.cfg.MaxIdleConnsPerHost)
+	l.envInt(EnvMaxConnsPerHost, &l.cfg.MaxConnsPerHost)
+	l.envDuration(EnvIdleConnTimeout, &l.cfg.IdleConnTimeout)
+}
+
+// readProfile fills the region from the AWS shared-config profile when no other source set it.
+func (l *loader) readProfile(name string) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Profile:           name,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		l.invalid("Profile", err.Error())
+		return
+	}
+
+	if l.cfg.Region == "" {
+		l.cfg.Region = aws.StringValue(sess.Config.Region)
+	}
+}
+
+func (l *loader) envString(key string, dst *string) {
+	if v, ok := os.LookupEnv(key); ok {
+		*dst = v
+	}
+}
+
+func (l *loader) envBool(key string, dst *bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.invalid(key, fmt.Sprintf("invalid boolean %q", v))
+		return
+	}
+	*dst = b
+}
+
+func (l *loader) envInt(key string, dst *int) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		l.invalid(key, fmt.Sprintf("invalid integer %q", v))
+		return
+	}
+	*dst = n
+}
+
+func (l *loader) envDuration(key string, dst *time.Duration) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		l.invalid(key, fmt.Sprintf("invalid duration %q", v))
+		return
+	}
+	*dst = d
+}
@@ -0,0 +1,79 @@
+package sthree_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/avila-r/sthree"
+)
+
+func Test_Load(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write config file - %v", err.Error())
+		}
+		return path
+	}
+
+	t.Run("environment overrides yaml file", func(t *testing.T) {
+		path := write("sthree.yaml", `
+region: us-east-1
+endpoint: http://localhost:9000
+force_path_style: true
+idle_conn_timeout: 30s
+`)
+		t.Setenv(sthree.EnvConfigFile, path)
+		t.Setenv(sthree.EnvRegion, "eu-west-1")
+
+		cfg, err := sthree.Load()
+		if err != nil {
+			t.Fatalf("failed to load config - %v", err.Error())
+		}
+
+		if cfg.Region != "eu-west-1" {
+			t.Errorf("unexpected region - %v", cfg.Region)
+		}
+		if cfg.Endpoint != "http://localhost:9000" || !cfg.ForcePathStyle {
+			t.Errorf("file settings were not applied - %+v", cfg)
+		}
+		if cfg.IdleConnTimeout != 30*time.Second {
+			t.Errorf("unexpected idle timeout - %v", cfg.IdleConnTimeout)
+		}
+	})
+
+	t.Run("json file", func(t *testing.T) {
+		path := write("sthree.json", `{"region": "sa-east-1", "credentials": {"access_key_id": "id", "secret_access_key": "secret"}}`)
+
+		cfg, err := sthree.Load(sthree.FromFile(path), sthree.WithoutEnv())
+		if err != nil {
+			t.Fatalf("failed to load config - %v", err.Error())
+		}
+
+		if cfg.Region != "sa-east-1" || cfg.Credentials.AccessKeyID != "id" {
+			t.Errorf("json settings were not applied - %+v", cfg)
+		}
+	})
+
+	t.Run("every invalid field is reported", func(t *testing.T) {
+		t.Setenv(sthree.EnvConfigFile, "")
+		t.Setenv(sthree.EnvMaxIdleConns, "many")
+		t.Setenv(sthree.EnvDisableSSL, "sometimes")
+		t.Setenv(sthree.EnvEndpoint, "ftp://localhost")
+
+		_, err := sthree.Load()
+
+		var cfgErr *sthree.ConfigError
+		if !errors.As(err, &cfgErr) {
+			t.Fatalf("expected config error - %v", err)
+		}
+		if len(cfgErr.Fields) != 3 {
+			t.Errorf("expected 3 invalid fields - %v", err.Error())
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package sthree
+
+import (
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// Operation describes a single S3 call flowing through the middleware chain.
+type Operation = middleware.Operation
+
+// Handler executes an operation.
+type Handler = middleware.Handler
+
+// Middleware wraps a Handler with additional behavior, such as logging,
+// metrics, authorization checks or request mutation.
+type Middleware = middleware.Middleware
+
+// Use appends middlewares to the chain every S3 call made through this client runs through,
+// including the calls of modules derived from it before `Use` was called.
+//
+// The first middleware added is the outermost one.
+//
+// @param middlewares The middlewares to append.
+func (m *Sthree) Use(middlewares ...Middleware) {
+	m.chain.Use(middlewares...)
+}
@@ -0,0 +1,156 @@
+package sthree_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/retry"
+)
+
+func Test_Middleware(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	seen := []sthree.Operation{}
+	client.Use(func(next sthree.Handler) sthree.Handler {
+		return func(ctx context.Context, op *sthree.Operation) error {
+			err := next(ctx, op)
+			seen = append(seen, *op)
+			return err
+		}
+	})
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Put("logo.json", map[string]string{"name": "logo"}); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("expected 2 operations, got %v", len(seen))
+	}
+
+	put := seen[1]
+	if put.Name != "PutObject" || put.Bucket != "assets" || put.Key != "logo.json" {
+		t.Errorf("unexpected operation - %+v", put)
+	}
+	if put.StatusCode != 200 || put.RequestID == "" || put.Duration <= 0 {
+		t.Errorf("response details were not recorded - %+v", put)
+	}
+
+	t.Run("middleware can short-circuit calls", func(t *testing.T) {
+		denied := errors.New("denied")
+		client.Use(func(next sthree.Handler) sthree.Handler {
+			return func(ctx context.Context, op *sthree.Operation) error {
+				if op.Name == "DeleteObject" {
+					return denied
+				}
+				return next(ctx, op)
+			}
+		})
+
+		if _, err := client.Bucket("assets").Delete("logo.json"); !errors.Is(err, denied) {
+			t.Errorf("expected delete to be denied - %v", err)
+		}
+
+		req, _ := client.Requests.DeleteObject(objects.Delete{Bucket: "assets", Key: "logo.json"})
+		if err := req.Send(); !errors.Is(err, denied) {
+			t.Errorf("expected request to be denied - %v", err)
+		}
+	})
+}
+
+func Test_Middleware_NoOutput(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	// The middleware answers every operation without calling next and without setting an output.
+	client.Use(func(next sthree.Handler) sthree.Handler {
+		return func(ctx context.Context, op *sthree.Operation) error {
+			return nil
+		}
+	})
+
+	bucket := client.Bucket("assets")
+	calls := map[string]func() error{
+		"Put":    func() error { _, err := bucket.Put("logo.json", "logo"); return err },
+		"Get":    func() error { _, err := bucket.Get("logo.json"); return err },
+		"Delete": func() error { _, err := bucket.Delete("logo.json"); return err },
+		"List":   func() error { _, err := bucket.List(); return err },
+		"Upload": func() error {
+			_, err := bucket.Upload(objects.Upload{Body: strings.NewReader("logo"), ObjectDetails: objects.ObjectDetails{Key: "logo.json"}})
+			return err
+		},
+		"Download":       func() error { _, err := bucket.Download("logo.json", &writerAt{}); return err },
+		"Buckets.Create": func() error { _, err := client.Buckets.Create("logs"); return err },
+		"Buckets.Delete": func() error { _, err := client.Buckets.Delete("logs"); return err },
+		"Buckets.List":   func() error { _, err := client.Buckets.List(); return err },
+	}
+
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, middleware.ErrNoOutput) {
+			t.Errorf("expected %v to fail without output - %v", name, err)
+		}
+	}
+}
+
+func Test_Middleware_Attach(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client, _ := mock.Connect(t, handler,
+		sthree.WithRetry(retry.Policy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+
+	var attempts, applied, completed atomic.Int32
+	options := 0
+	client.Use(func(next sthree.Handler) sthree.Handler {
+		return func(ctx context.Context, op *sthree.Operation) error {
+			attempts.Add(1)
+			op.Options = append(op.Options, func(r *request.Request) {
+				applied.Add(1)
+				r.Handlers.Complete.PushBack(func(*request.Request) {
+					completed.Add(1)
+				})
+			})
+			options = len(op.Options)
+
+			return next(ctx, op)
+		}
+	})
+
+	req, _ := client.Requests.DeleteObject(objects.Delete{Bucket: "assets", Key: "logo.json"})
+	if err := req.Send(); err != nil {
+		t.Fatalf("failed to send request - %v", err.Error())
+	}
+
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("expected the chain to run once per attempt, got %v runs", n)
+	}
+	if applied.Load() != 1 || completed.Load() != 1 {
+		t.Errorf("expected the options of the middlewares to be applied once, got %v applications and %v completions", applied.Load(), completed.Load())
+	}
+	if options != 1 {
+		t.Errorf("expected the options not to accumulate across attempts, got %v", options)
+	}
+}
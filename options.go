@@ -0,0 +1,315 @@
+package sthree
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/avila-r/sthree/pkg/audit"
+	"github.com/avila-r/sthree/pkg/breaker"
+	"github.com/avila-r/sthree/pkg/guard"
+	"github.com/avila-r/sthree/pkg/logging"
+	"github.com/avila-r/sthree/pkg/metrics"
+	"github.com/avila-r/sthree/pkg/ratelimit"
+	"github.com/avila-r/sthree/pkg/retry"
+	"github.com/avila-r/sthree/pkg/s3api"
+	"github.com/avila-r/sthree/pkg/tracing"
+)
+
+// Option customizes the `Config` used to build a `Sthree` client.
+//
+// Options are applied in order, so later options override earlier ones.
+// A `Config` value is itself an Option that replaces every setting applied before it.
+type Option interface {
+	apply(*Config)
+}
+
+// OptionFunc adapts an ordinary function into an `Option`.
+type OptionFunc func(*Config)
+
+func (f OptionFunc) apply(c *Config) {
+	f(c)
+}
+
+func (c Config) apply(dst *Config) {
+	*dst = c
+}
+
+// WithRegion sets the AWS region the client sends requests to.
+//
+// @param region The AWS region (e.g., "us-east-1").
+// @return An Option that sets `Config.Region`.
+func WithRegion(region string) Option {
+	return OptionFunc(func(c *Config) {
+		c.Region = region
+	})
+}
+
+// WithEndpoint overrides the S3 endpoint URL, typically to target S3-compatible
+// providers such as MinIO or LocalStack.
+//
+// @param endpoint The endpoint URL (e.g., "http://localhost:9000").
+// @return An Option that sets `Config.Endpoint`.
+func WithEndpoint(endpoint string) Option {
+	return OptionFunc(func(c *Config) {
+		c.Endpoint = endpoint
+	})
+}
+
+// WithPathStyle forces path-style addressing ("endpoint/bucket/key").
+//
+// @return An Option that sets `Config.ForcePathStyle`.
+func WithPathStyle() Option {
+	return OptionFunc(func(c *Config) {
+		c.ForcePathStyle = true
+	})
+}
+
+// WithoutSSL sends requests over plain HTTP.
+//
+// @return An Option that sets `Config.DisableSSL`.
+func WithoutSSL() Option {
+	return OptionFunc(func(c *Config) {
+		c.DisableSSL = true
+	})
+}
+
+// WithRegionDiscovery discovers the region of each bucket on first use and routes its
+// requests through a lazily created client for that region.
+//
+// @return An Option that sets `Config.DiscoverRegions`.
+func WithRegionDiscovery() Option {
+	return OptionFunc(func(c *Config) {
+		c.DiscoverRegions = true
+	})
+}
+
+// WithStaticCredentials authenticates with a fixed access key pair.
+//
+// @param id The access key ID.
+// @param secret The secret access key.
+// @param token The optional session token of temporary credentials.
+// @return An Option that sets `Config.Credentials`.
+func WithStaticCredentials(id, secret, token string) Option {
+	return OptionFunc(func(c *Config) {
+		c.Credentials = Credentials{
+			AccessKeyID:     id,
+			SecretAccessKey: secret,
+			SessionToken:    token,
+		}
+	})
+}
+
+// WithEnvCredentials authenticates with the credentials found in the AWS_* environment variables.
+//
+// @return An Option that sets `Config.Credentials`.
+func WithEnvCredentials() Option {
+	return OptionFunc(func(c *Config) {
+		c.Credentials = Credentials{FromEnv: true}
+	})
+}
+
+// WithAPI builds the modules on a custom S3 API implementation instead of the AWS S3 SDK client,
+// such as a fake in tests, or `sdkv2.API` to run on aws-sdk-go-v2. The AWS configuration
+// provider may be nil in that case.
+//
+// @param api The S3 API implementation; it also serves `Sthree.Requests` if it implements `s3api.Requester`.
+// @return An Option that sets `Config.API`.
+func WithAPI(api s3api.API) Option {
+	return OptionFunc(func(c *Config) {
+		c.API = api
+	})
+}
+
+// WithProfile reads credentials (and, through `Session`, the region) from a named AWS shared-config profile.
+//
+// @param name The name of the profile.
+// @return An Option that sets `Config.Profile`.
+func WithProfile(name string) Option {
+	return OptionFunc(func(c *Config) {
+		c.Profile = name
+	})
+}
+
+// WithHTTPClient sends every request through the given HTTP client.
+//
+// @param client The HTTP client to use.
+// @return An Option that sets `Config.HTTPClient`.
+func WithHTTPClient(client *http.Client) Option {
+	return OptionFunc(func(c *Config) {
+		c.HTTPClient = client
+	})
+}
+
+// WithConnectionPool tunes the connection pool of the default HTTP transport.
+// Zero values keep the transport defaults.
+//
+// @param maxIdle The maximum number of idle connections across all hosts.
+// @param maxIdlePerHost The maximum number of idle connections per host.
+// @param maxPerHost The maximum number of connections per host.
+// @param idleTimeout How long an idle connection is kept before being closed.
+// @return An Option that sets the connection-pool fields of `Config`.
+func WithConnectionPool(maxIdle, maxIdlePerHost, maxPerHost int, idleTimeout time.Duration) Option {
+	return OptionFunc(func(c *Config) {
+		c.MaxIdleConns = maxIdle
+		c.MaxIdleConnsPerHost = maxIdlePerHost
+		c.MaxConnsPerHost = maxPerHost
+		c.IdleConnTimeout = idleTimeout
+	})
+}
+
+// WithRetry replaces the default retryer of the AWS SDK with the given policy.
+//
+// @param policy The retry policy applied to every call of the client.
+// @return An Option that sets `Config.Retry`.
+func WithRetry(policy retry.Policy) Option {
+	return OptionFunc(func(c *Config) {
+		c.Retry = &policy
+	})
+}
+
+// WithUpload tunes the multipart uploads of the client: the part size, scaled up automatically for
+// objects that would need more than 10,000 parts, the number of parts sent in parallel, and whether
+// the parts of failed uploads are kept instead of aborted.
+//
+// @param cfg The part size, concurrency and error handling of the uploads.
+// @return An Option that sets `Config.Upload`.
+func WithUpload(cfg UploadConfig) Option {
+	return OptionFunc(func(c *Config) {
+		c.Upload = cfg
+	})
+}
+
+// WithDownload tunes the ranged downloads of the client: the size of the byte ranges
+// fetched and the number of ranges fetched in parallel.
+//
+// @param cfg The part size and concurrency of the downloads.
+// @return An Option that sets `Config.Download`.
+func WithDownload(cfg DownloadConfig) Option {
+	return OptionFunc(func(c *Config) {
+		c.Download = cfg
+	})
+}
+
+// WithRateLimit throttles the operations of the client with token buckets scoped globally,
+// per bucket and per key prefix, for reads and writes separately.
+//
+// @param cfg The limits to enforce, and whether to adapt them to SlowDown responses.
+// @return An Option that sets `Config.RateLimit`.
+func WithRateLimit(cfg ratelimit.Config) Option {
+	return OptionFunc(func(c *Config) {
+		c.RateLimit = &cfg
+	})
+}
+
+// WithCircuitBreaker fails operations fast, with an `errs.ErrCircuitOpen` error, while too many
+// operations against the endpoint or bucket are failing, and probes the backend once the cool-down elapses.
+//
+// @param cfg The scope and thresholds of the breaker.
+// @return An Option that sets `Config.CircuitBreaker`.
+func WithCircuitBreaker(cfg breaker.Config) Option {
+	return OptionFunc(func(c *Config) {
+		c.CircuitBreaker = &cfg
+	})
+}
+
+// WithGuard enforces a safety policy on the client: mutating operations are rejected, with an
+// `errs.ErrProtected` error and without contacting S3, in read-only mode or when they would delete
+// or overwrite a bucket or key the policy protects.
+//
+// @param policy The read-only mode and the allow and deny lists of buckets and key prefixes.
+// @return An Option that sets `Config.Guard`.
+func WithGuard(policy guard.Policy) Option {
+	return OptionFunc(func(c *Config) {
+		c.Guard = &policy
+	})
+}
+
+// WithReadOnly rejects every mutating operation of the client with an `errs.ErrReadOnly` error,
+// keeping the rest of the safety policy, if any.
+//
+// @return An Option that sets `Config.Guard.ReadOnly`.
+func WithReadOnly() Option {
+	return OptionFunc(func(c *Config) {
+		if c.Guard == nil {
+			c.Guard = &guard.Policy{}
+		}
+		c.Guard.ReadOnly = true
+	})
+}
+
+// WithAudit records every mutating operation sent by the client (puts, uploads, deletes, bucket
+// creations and deletions, and CORS changes) with its principal, target, version, ETag and outcome.
+// Operations rejected by the guard or recorded in dry-run mode are not audited, as they change nothing.
+//
+// @param auditor The auditor to record into (e.g., `audit.New(audit.Config{Sink: sink})`).
+// @return An Option that sets `Config.Audit`.
+func WithAudit(auditor *audit.Auditor) Option {
+	return OptionFunc(func(c *Config) {
+		c.Audit = auditor
+	})
+}
+
+// WithDryRun validates and records the mutating operations of the client (puts, uploads, deletes,
+// bucket creations and deletions, and CORS changes) with their fully built SDK input, without
+// sending them. The recorded plan is available in `Sthree.Plan`.
+//
+// @return An Option that sets `Config.DryRun`.
+func WithDryRun() Option {
+	return OptionFunc(func(c *Config) {
+		c.DryRun = true
+	})
+}
+
+// WithTracer records a span for every S3 operation issued through the client, carrying its bucket,
+// key, status code, AWS request ID, retries and bytes transferred. The spans of composite operations,
+// such as multipart uploads, have a child span for each request they issue.
+//
+// @param tracer The tracer spans are started with (e.g., an `otel.Tracer`).
+// @return An Option that sets `Config.Tracer`.
+func WithTracer(tracer tracing.Tracer) Option {
+	return OptionFunc(func(c *Config) {
+		c.Tracer = tracer
+	})
+}
+
+// WithMetrics records every S3 operation issued through the client in the collector: requests
+// by operation, bucket and status code, latency histograms, and bytes sent and received.
+//
+// @param collector The collector to record into; serve it with `collector.Handler()`.
+// @return An Option that sets `Config.Metrics`.
+func WithMetrics(collector *metrics.Collector) Option {
+	return OptionFunc(func(c *Config) {
+		c.Metrics = collector
+	})
+}
+
+// WithLogger logs every S3 operation issued through the client with its operation, bucket, key,
+// status code, AWS request ID, retries and latency. SSE-C keys and signatures are redacted.
+//
+// @param logger The logger records are written to.
+// @param opts Optional levels and contents of the records.
+// @return An Option that sets `Config.Logger` and `Config.Logging`.
+func WithLogger(logger *slog.Logger, opts ...logging.Options) Option {
+	return OptionFunc(func(c *Config) {
+		c.Logger = logger
+		if len(opts) > 0 {
+			c.Logging = opts[0]
+		}
+	})
+}
+
+// newConfig applies the given options, in order, over an empty `Config` and validates the result.
+//
+// @param opts The options to apply.
+// @return The resulting `Config`, or a `*ConfigError` if any field is invalid.
+func newConfig(opts ...Option) (Config, error) {
+	cfg := Config{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.apply(&cfg)
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// Outcomes of an audited operation.
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+// Entry is the audit record of a single mutating operation.
+type Entry struct {
+	// Time is when the operation started.
+	Time time.Time `json:"time"`
+
+	// Principal identifies who issued the operation, as supplied by the caller.
+	Principal string `json:"principal,omitempty"`
+
+	// Operation is the name of the operation (e.g., "DeleteObject").
+	Operation string `json:"operation"`
+
+	// Bucket is the bucket targeted by the operation, if any.
+	Bucket string `json:"bucket,omitempty"`
+
+	// Key is the object key targeted by the operation, if any.
+	Key string `json:"key,omitempty"`
+
+	// VersionID is the version of the object written or deleted, if versioning is enabled.
+	VersionID string `json:"version_id,omitempty"`
+
+	// ETag is the entity tag of the object written, if any.
+	ETag string `json:"etag,omitempty"`
+
+	// Outcome is either `Success` or `Failure`.
+	Outcome string `json:"outcome"`
+
+	// Error is the reason the operation failed.
+	Error string `json:"error,omitempty"`
+
+	// StatusCode is the HTTP status code of the last response, if any.
+	StatusCode int `json:"status_code,omitempty"`
+
+	// RequestID is the AWS request ID of the last response, if any.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Sink stores audit entries. Implementations must be safe for concurrent use.
+type Sink interface {
+	// Write stores an entry.
+	Write(entry Entry) error
+
+	// Close flushes the buffered entries, if any, and releases the sink.
+	Close() error
+}
+
+// Config configures an `Auditor`.
+type Config struct {
+	// Sink stores the entries.
+	Sink Sink
+
+	// Principal is recorded for operations whose context carries no principal (see `WithPrincipal`).
+	Principal string
+
+	// OnError is called with the entries the sink failed to store. The audited operations
+	// are not failed, as they already reached S3. Errors are dropped when nil.
+	//
+	// Sinks storing entries in the background, like `S3Sink`, report their errors to it as well.
+	OnError func(entry Entry, err error)
+}
+
+// Auditor records every mutating operation of a client (see `middleware.Mutating`) to a sink,
+// whether it succeeded or failed.
+type Auditor struct {
+	cfg Config
+}
+
+// New creates an auditor from the given configuration.
+//
+// @param cfg The sink and the default principal of the auditor.
+// @return A pointer to a new `Auditor`.
+func New(cfg Config) *Auditor {
+	if sink, ok := cfg.Sink.(interface {
+		setOnError(fn func(entry Entry, err error))
+	}); ok && cfg.OnError != nil {
+		sink.setOnError(cfg.OnError)
+	}
+
+	return &Auditor{cfg: cfg}
+}
+
+// Close closes the sink of the auditor, flushing its buffered entries.
+//
+// @return An error if the sink failed to flush or close.
+func (a *Auditor) Close() error {
+	return a.cfg.Sink.Close()
+}
+
+type principalKey struct{}
+
+// WithPrincipal attaches a principal to the context, recorded for the operations issued with it.
+//
+// @param ctx The parent context.
+// @param principal The identity of the caller (e.g., a user name or a job name).
+// @return A context carrying the principal.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Principal returns the principal attached to the context by `WithPrincipal`.
+//
+// @param ctx The context of an operation.
+// @return The principal, or an empty string.
+func Principal(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// Middleware returns a middleware that writes an entry for every mutating operation once it completes.
+// The requests returned unsent by `Sthree.Requests` run the chain once per attempt (see `middleware.Attach`),
+// so an entry is written for every attempt of a retried request.
+//
+// @return A middleware auditing the mutating operations.
+func (a *Auditor) Middleware() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, op *middleware.Operation) error {
+			if !middleware.Mutating(op.Name) {
+				return next(ctx, op)
+			}
+
+			start := time.Now()
+			err := next(ctx, op)
+
+			entry := Entry{
+				Time:       start,
+				Principal:  Principal(ctx),
+				Operation:  op.Name,
+				Bucket:     op.Bucket,
+				Key:        op.Key,
+				Outcome:    Success,
+				StatusCode: op.StatusCode,
+				RequestID:  op.RequestID,
+			}
+			if entry.Principal == "" {
+				entry.Principal = a.cfg.Principal
+			}
+			if err != nil {
+				entry.Outcome, entry.Error = Failure, err.Error()
+			}
+			entry.VersionID, entry.ETag = version(op)
+
+			if werr := a.cfg.Sink.Write(entry); werr != nil && a.cfg.OnError != nil {
+				a.cfg.OnError(entry, werr)
+			}
+
+			return err
+		}
+	}
+}
+
+// version returns the version ID and the entity tag of the object affected by an operation.
+func version(op *middleware.Operation) (string, string) {
+	switch output := op.Output.(type) {
+	case *s3.PutObjectOutput:
+		if output != nil {
+			return aws.StringValue(output.VersionId), aws.StringValue(output.ETag)
+		}
+	case *s3manager.UploadOutput:
+		if output != nil {
+			return aws.StringValue(output.VersionID), aws.StringValue(output.ETag)
+		}
+	case *s3.DeleteObjectOutput:
+		if output != nil && output.VersionId != nil {
+			return aws.StringValue(output.VersionId), ""
+		}
+	}
+
+	if input, ok := op.Input.(*s3.DeleteObjectInput); ok {
+		return aws.StringValue(input.VersionId), ""
+	}
+
+	return "", ""
+}
@@ -0,0 +1,251 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/audit"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+// open opens another client on the server of a test.
+func open(t *testing.T, server *mock.Server, opts ...sthree.Option) *sthree.Sthree {
+	t.Helper()
+
+	client, err := sthree.Open(session.Must(session.NewSession()), append(mock.Options(server.URL), opts...)...)
+	if err != nil {
+		t.Fatalf("failed to open client - %v", err.Error())
+	}
+
+	return client
+}
+
+func Test_FileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := audit.NewFileSink(path)
+	if err != nil {
+		t.Fatalf("failed to open sink - %v", err.Error())
+	}
+
+	auditor := audit.New(audit.Config{Sink: sink, Principal: "cleanup-job"})
+	client, _ := mock.Client(t, sthree.WithAudit(auditor))
+
+	ctx := audit.WithPrincipal(context.Background(), "alice")
+	if _, err := client.Buckets.CreateWithContext(ctx, "assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Put("logo.txt", "logo"); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").List(); err != nil {
+		t.Fatalf("failed to list objects - %v", err.Error())
+	}
+	client.Bucket("missing").Delete("logo.txt")
+
+	if err := auditor.Close(); err != nil {
+		t.Fatalf("failed to close auditor - %v", err.Error())
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log - %v", err.Error())
+	}
+	defer file.Close()
+
+	entries := []audit.Entry{}
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		entry := audit.Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("failed to decode entry - %v", err.Error())
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", entries)
+	}
+
+	create, put, remove := entries[0], entries[1], entries[2]
+	if create.Operation != "CreateBucket" || create.Principal != "alice" || create.Outcome != audit.Success {
+		t.Errorf("unexpected create entry - %+v", create)
+	}
+	if put.Operation != "PutObject" || put.Principal != "cleanup-job" || put.Key != "logo.txt" || put.ETag == "" {
+		t.Errorf("unexpected put entry - %+v", put)
+	}
+	if remove.Operation != "DeleteObject" || remove.Outcome != audit.Failure || remove.Error == "" || remove.StatusCode != 404 {
+		t.Errorf("unexpected delete entry - %+v", remove)
+	}
+}
+
+func Test_S3Sink(t *testing.T) {
+	plain, server := mock.Client(t)
+	if _, err := plain.Buckets.Create("audit"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	sink := audit.NewS3Sink(plain.API, "audit", "logs/")
+	sink.BatchSize = 2
+
+	client := open(t, server, sthree.WithAudit(audit.New(audit.Config{Sink: sink})))
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	for _, key := range []string{"a.txt", "b.txt"} {
+		if _, err := client.Bucket("assets").Put(key, key); err != nil {
+			t.Fatalf("failed to put object - %v", err.Error())
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("failed to close sink - %v", err.Error())
+	}
+
+	page, err := plain.Bucket("audit").List()
+	if err != nil {
+		t.Fatalf("failed to list audit objects - %v", err.Error())
+	}
+	if len(page.Objects) != 2 {
+		t.Fatalf("expected 2 rolled objects, got %+v", page.Objects)
+	}
+
+	lines := 0
+	for _, object := range page.Objects {
+		if !strings.HasPrefix(object.Key, "logs/") || !strings.HasSuffix(object.Key, ".jsonl") {
+			t.Errorf("unexpected object key %v", object.Key)
+		}
+		body, _ := server.Object("audit", object.Key)
+		lines += strings.Count(string(body), "\n")
+	}
+	if lines != 3 {
+		t.Errorf("expected 3 entries across objects, got %v", lines)
+	}
+}
+
+// failing fails the first puts, and blocks the puts while unblocked is not closed.
+type failing struct {
+	s3api.API
+	failures  atomic.Int32
+	started   chan struct{}
+	unblocked chan struct{}
+}
+
+func (f *failing) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	if f.started != nil {
+		f.started <- struct{}{}
+	}
+	if f.unblocked != nil {
+		select {
+		case <-f.unblocked:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.failures.Add(-1) >= 0 {
+		return nil, errors.New("unavailable")
+	}
+
+	return f.API.PutObjectWithContext(ctx, input, opts...)
+}
+
+func Test_S3Sink_Errors(t *testing.T) {
+	plain, server := mock.Client(t)
+	if _, err := plain.Buckets.Create("audit"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	t.Run("interval flushes report errors and retry", func(t *testing.T) {
+		api := &failing{API: plain.API}
+		api.failures.Store(2)
+
+		sink := audit.NewS3Sink(api, "audit", "retried/")
+		sink.FlushInterval = 10 * time.Millisecond
+		sink.MaxBuffered = 2
+
+		reported := make(chan error, 10)
+		audit.New(audit.Config{Sink: sink, OnError: func(entry audit.Entry, err error) {
+			reported <- err
+		}})
+
+		for _, key := range []string{"a.txt", "b.txt"} {
+			if err := sink.Write(audit.Entry{Operation: "PutObject", Key: key}); err != nil {
+				t.Fatalf("failed to write entry - %v", err.Error())
+			}
+		}
+		if err := sink.Write(audit.Entry{Operation: "PutObject", Key: "c.txt"}); !errors.Is(err, audit.ErrBufferFull) {
+			t.Errorf("expected buffer full error, got %v", err)
+		}
+
+		for i := 0; i < 4; i++ {
+			select {
+			case <-reported:
+			case <-time.After(time.Second):
+				t.Fatalf("expected failed interval flushes to be reported, got %d errors", i)
+			}
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for {
+			page, err := plain.Bucket("audit").List(objects.List{Prefix: "retried/"})
+			if err == nil && len(page.Objects) == 1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected interval flush to be retried until stored")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	t.Run("objects are stored outside the lock with a timeout", func(t *testing.T) {
+		api := &failing{API: plain.API, started: make(chan struct{}, 1), unblocked: make(chan struct{})}
+
+		sink := audit.NewS3Sink(api, "audit", "blocked/")
+		sink.FlushTimeout = 50 * time.Millisecond
+		sink.Write(audit.Entry{Operation: "PutObject", Key: "a.txt"})
+
+		flushed := make(chan error, 1)
+		go func() { flushed <- sink.Flush() }()
+		<-api.started
+
+		start := time.Now()
+		if err := sink.Write(audit.Entry{Operation: "PutObject", Key: "b.txt"}); err != nil {
+			t.Errorf("failed to write entry - %v", err.Error())
+		}
+		if elapsed := time.Since(start); elapsed > 25*time.Millisecond {
+			t.Errorf("expected write not to wait for the flush, took %v", elapsed)
+		}
+
+		if err := <-flushed; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected flush to time out, got %v", err)
+		}
+
+		close(api.unblocked)
+		if err := sink.Close(); err != nil {
+			t.Fatalf("failed to close sink - %v", err.Error())
+		}
+
+		page, _ := plain.Bucket("audit").List(objects.List{Prefix: "blocked/"})
+		if len(page.Objects) != 1 {
+			t.Fatalf("expected 1 object, got %+v", page.Objects)
+		}
+		body, _ := server.Object("audit", page.Objects[0].Key)
+		if lines := strings.Count(string(body), "\n"); lines != 2 {
+			t.Errorf("expected the timed out entries to be stored with the new ones, got %v", lines)
+		}
+	})
+}
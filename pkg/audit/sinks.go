@@ -0,0 +1,275 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+// FileSink appends entries to a file in the JSON Lines format, one entry per line.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens a file for appending, creating it if needed.
+//
+// @param path The path of the file.
+// @return A pointer to a `FileSink`, or an error if the file cannot be opened.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// Write appends the entry to the file.
+func (s *FileSink) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// Defaults applied to the zero fields of an `S3Sink`.
+const (
+	DefaultBatchSize     = 1000
+	DefaultFlushInterval = time.Minute
+	DefaultFlushTimeout  = 30 * time.Second
+)
+
+// ErrBufferFull is returned by `S3Sink.Write` when the sink already buffers `MaxBuffered` entries,
+// because the previous objects could not be stored.
+var ErrBufferFull = errors.New("audit: buffer full")
+
+// S3Sink batches entries into JSON Lines objects stored in a bucket, rolling to a new object
+// every `BatchSize` entries or every `FlushInterval`, whichever comes first.
+//
+// Objects are named after the time they are rolled (e.g., "audit/2024/05/01/120000.000000000-1.jsonl"),
+// so they list in chronological order. Entries that fail to be stored stay buffered for the next roll,
+// up to `MaxBuffered` entries.
+type S3Sink struct {
+	// API stores the objects. Pass `Sthree.API` rather than a client whose operations are
+	// audited, so that storing the entries is not audited itself.
+	API s3api.API
+
+	// Bucket is the bucket the objects are stored in.
+	Bucket string
+
+	// Prefix is prepended to the key of every object (e.g., "audit/").
+	Prefix string
+
+	// BatchSize is the number of entries that rolls an object; defaults to `DefaultBatchSize`.
+	BatchSize int
+
+	// FlushInterval is the longest time an entry stays buffered; defaults to `DefaultFlushInterval`.
+	FlushInterval time.Duration
+
+	// FlushTimeout bounds the time spent storing each object; defaults to `DefaultFlushTimeout`.
+	FlushTimeout time.Duration
+
+	// MaxBuffered is the number of entries buffered past which new entries are rejected
+	// with `ErrBufferFull`; defaults to ten times the batch size.
+	MaxBuffered int
+
+	// OnError is called with the entries of the objects that failed to be stored when the flush
+	// interval elapsed; they stay buffered and are retried once the interval elapses again.
+	// An `Auditor` sets it to its own `OnError` when nil.
+	OnError func(entry Entry, err error)
+
+	// flushing serializes the flushes, so that objects are rolled in order.
+	flushing sync.Mutex
+
+	mu       sync.Mutex
+	entries  []Entry
+	inflight int
+	rolled   int
+	timer    *time.Timer
+}
+
+// NewS3Sink creates a sink storing batches of entries in a bucket.
+//
+// @param api The S3 API to store the objects with (e.g., `Sthree.API`).
+// @param bucket The bucket the objects are stored in.
+// @param prefix The prefix of the object keys.
+// @return A pointer to an `S3Sink` with the default batch size and flush interval.
+func NewS3Sink(api s3api.API, bucket, prefix string) *S3Sink {
+	return &S3Sink{API: api, Bucket: bucket, Prefix: prefix}
+}
+
+// Write buffers the entry, and stores the batch if it is full.
+//
+// @return `ErrBufferFull` if the entry cannot be buffered, or an error if the full batch cannot be stored.
+func (s *S3Sink) Write(entry Entry) error {
+	s.mu.Lock()
+
+	size := s.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	limit := s.MaxBuffered
+	if limit <= 0 {
+		limit = 10 * size
+	}
+	if len(s.entries)+s.inflight >= limit {
+		s.mu.Unlock()
+		return ErrBufferFull
+	}
+
+	s.entries = append(s.entries, entry)
+	full := len(s.entries) >= size
+	if !full {
+		s.arm()
+	}
+	s.mu.Unlock()
+
+	if full {
+		_, err := s.flush()
+		return err
+	}
+
+	return nil
+}
+
+// Flush stores the buffered entries, if any, in a new object.
+//
+// @return An error if the object cannot be stored; the entries stay buffered.
+func (s *S3Sink) Flush() error {
+	_, err := s.flush()
+	return err
+}
+
+// Close stores the buffered entries.
+func (s *S3Sink) Close() error {
+	return s.Flush()
+}
+
+// setOnError reports the errors of the interval flushes to the auditor, unless already set.
+func (s *S3Sink) setOnError(fn func(entry Entry, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.OnError == nil {
+		s.OnError = fn
+	}
+}
+
+// arm starts the flush interval of the buffered entries, if not already started.
+// It must be called with the lock held.
+func (s *S3Sink) arm() {
+	if s.timer != nil || len(s.entries) == 0 {
+		return
+	}
+
+	interval := s.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	s.timer = time.AfterFunc(interval, func() {
+		batch, err := s.flush()
+		if err == nil {
+			return
+		}
+
+		s.mu.Lock()
+		onError := s.OnError
+		s.mu.Unlock()
+		if onError != nil {
+			for _, entry := range batch {
+				onError(entry, err)
+			}
+		}
+	})
+}
+
+// flush stores the buffered entries in a new object. The lock is not held while the object is
+// stored, so entries can still be written meanwhile. On failure, the entries are buffered again
+// ahead of the ones written meanwhile, and the flush interval is restarted.
+func (s *S3Sink) flush() ([]Entry, error) {
+	s.flushing.Lock()
+	defer s.flushing.Unlock()
+
+	s.mu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	batch := s.entries
+	s.entries = nil
+	s.inflight = len(batch)
+	s.rolled++
+	rolled := s.rolled
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil, nil
+	}
+
+	err := s.put(batch, rolled)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inflight = 0
+	if err != nil {
+		s.entries = append(batch, s.entries...)
+	}
+	s.arm()
+
+	return batch, err
+}
+
+// put stores the batch in the object rolled at the given position.
+func (s *S3Sink) put(batch []Entry, rolled int) error {
+	var body bytes.Buffer
+	for _, entry := range batch {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		body.Write(append(line, '\n'))
+	}
+
+	timeout := s.FlushTimeout
+	if timeout <= 0 {
+		timeout = DefaultFlushTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	key := fmt.Sprintf("%s%s-%d.jsonl", s.Prefix, time.Now().UTC().Format("2006/01/02/150405.000000000"), rolled)
+	_, err := s.API.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body.Bytes()),
+		ContentType: aws.String("application/x-ndjson"),
+	})
+
+	return err
+}
@@ -0,0 +1,349 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// Scope selects which operations share a circuit.
+type Scope string
+
+const (
+	// Endpoint shares a single circuit between every operation of the client.
+	Endpoint Scope = "endpoint"
+
+	// Bucket keeps an independent circuit per bucket.
+	Bucket Scope = "bucket"
+)
+
+// State is the state of a circuit.
+type State int
+
+const (
+	// Closed lets every operation through while counting failures.
+	Closed State = iota
+
+	// Open rejects every operation until the cool-down elapses.
+	Open
+
+	// HalfOpen lets a limited number of probe operations through to test the backend.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Defaults applied to the zero fields of a `Config`.
+const (
+	DefaultWindow           = 10 * time.Second
+	DefaultMinRequests      = 10
+	DefaultFailureRate      = 0.5
+	DefaultCoolDown         = 30 * time.Second
+	DefaultHalfOpenRequests = 1
+)
+
+// Config configures a circuit breaker. Zero fields use the defaults above.
+type Config struct {
+	// Scope selects which operations share a circuit; defaults to Endpoint.
+	Scope Scope `yaml:"scope"`
+
+	// Window is the period over which failures are counted while the circuit is closed.
+	Window time.Duration `yaml:"window"`
+
+	// MinRequests is the number of operations required in a window before the circuit can trip.
+	MinRequests int `yaml:"min_requests"`
+
+	// FailureRate is the ratio of failed operations, between 0 and 1, that trips the circuit.
+	FailureRate float64 `yaml:"failure_rate"`
+
+	// CoolDown is how long the circuit stays open before letting probe operations through.
+	CoolDown time.Duration `yaml:"cool_down"`
+
+	// HalfOpenRequests is the number of probe operations that must succeed to close the circuit.
+	HalfOpenRequests int `yaml:"half_open_requests"`
+}
+
+// OpenError is returned, without contacting S3, for operations rejected by an open circuit.
+// It matches `errs.ErrCircuitOpen` through `errors.Is`.
+type OpenError struct {
+	// Operation is the name of the rejected operation.
+	Operation string
+
+	// Circuit is the name of the open circuit: the bucket, or empty for the endpoint scope.
+	Circuit string
+
+	// Until is when the circuit lets probe operations through again.
+	Until time.Time
+}
+
+func (e *OpenError) Error() string {
+	if e.Circuit == "" {
+		return fmt.Sprintf("sthree: %s: circuit open until %s", e.Operation, e.Until.Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("sthree: %s %s: circuit open until %s", e.Operation, e.Circuit, e.Until.Format(time.RFC3339))
+}
+
+// Is reports whether the target is `errs.ErrCircuitOpen`.
+func (e *OpenError) Is(target error) bool {
+	return target == errs.ErrCircuitOpen
+}
+
+// Breaker trips circuits when too many of the operations flowing through them fail,
+// so callers fail fast instead of piling up on a backend that is down.
+// A Breaker is safe for concurrent use.
+type Breaker struct {
+	cfg      Config
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// New creates a circuit breaker from the given configuration.
+//
+// @param cfg The thresholds of the breaker.
+// @return A pointer to a new `Breaker`.
+func New(cfg Config) *Breaker {
+	if cfg.Scope == "" {
+		cfg.Scope = Endpoint
+	}
+	if cfg.Window == 0 {
+		cfg.Window = DefaultWindow
+	}
+	if cfg.MinRequests == 0 {
+		cfg.MinRequests = DefaultMinRequests
+	}
+	if cfg.FailureRate == 0 {
+		cfg.FailureRate = DefaultFailureRate
+	}
+	if cfg.CoolDown == 0 {
+		cfg.CoolDown = DefaultCoolDown
+	}
+	if cfg.HalfOpenRequests == 0 {
+		cfg.HalfOpenRequests = DefaultHalfOpenRequests
+	}
+
+	return &Breaker{
+		cfg:      cfg,
+		circuits: map[string]*circuit{},
+	}
+}
+
+// State returns the state of the circuit that operations on the bucket go through.
+//
+// @param bucket The bucket; ignored with the Endpoint scope.
+// @return The state of the circuit.
+func (b *Breaker) State(bucket string) State {
+	c := b.circuit(bucket)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == Open && !time.Now().Before(c.until) {
+		return HalfOpen
+	}
+
+	return c.state
+}
+
+// Middleware returns a middleware that rejects operations with an `*OpenError` while their circuit is open.
+// Nested operations (see `middleware.Nested`) are not recorded themselves, since each of their requests is.
+//
+// @return A middleware that records the outcome of every operation in its circuit.
+func (b *Breaker) Middleware() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, op *middleware.Operation) error {
+			if middleware.Nested(op.Name) {
+				return next(ctx, op)
+			}
+
+			c := b.circuit(op.Bucket)
+			if err := c.allow(op, b.cfg); err != nil {
+				return err
+			}
+
+			err := next(ctx, op)
+
+			// Errors of unsent requests reach the chain unwrapped, so classify them here.
+			wrapped := errs.Wrap(op.Name, op.Bucket, op.Key, err)
+			if Neutral(wrapped) {
+				c.release()
+				return err
+			}
+			c.done(Failure(wrapped), b.cfg)
+
+			return err
+		}
+	}
+}
+
+// neutral lists the AWS error codes of operations that failed before, or regardless of,
+// the backend: cancellations, invalid parameters and serialization errors.
+var neutral = map[string]bool{
+	request.CanceledErrorCode:       true,
+	request.InvalidParameterErrCode: true,
+	request.ErrCodeSerialization:    true,
+}
+
+// Failure reports whether an error counts as a failure of the backend: transport errors,
+// throttling and 5xx responses do, while client errors and neutral errors (see `Neutral`) do not.
+//
+// @param err The error returned by an operation.
+// @return Whether the error counts towards tripping the circuit.
+func Failure(err error) bool {
+	if err == nil || Neutral(err) {
+		return false
+	}
+
+	if errors.Is(err, errs.ErrThrottled) {
+		return true
+	}
+
+	status := errs.StatusCode(err)
+	return status == 0 || status >= http.StatusInternalServerError
+}
+
+// Neutral reports whether an error says nothing about the health of the backend, so the
+// operation counts neither as a success nor as a failure: cancellations and deadlines of the
+// caller's context, including those of the rate limiter, invalid parameters, serialization
+// errors, and operations rejected by the client itself.
+//
+// @param err The error returned by an operation.
+// @return Whether the error is ignored by the circuit.
+func Neutral(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, errs.ErrCircuitOpen) || errors.Is(err, errs.ErrProtected) {
+		return true
+	}
+
+	var e *errs.Error
+	if errors.As(err, &e) && neutral[e.Code] {
+		return true
+	}
+
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && neutral[aerr.Code()]
+}
+
+func (b *Breaker) circuit(bucket string) *circuit {
+	name := ""
+	if b.cfg.Scope == Bucket {
+		name = bucket
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[name]
+	if !ok {
+		c = &circuit{name: name, start: time.Now()}
+		b.circuits[name] = c
+	}
+
+	return c
+}
+
+// circuit is the state of a single circuit.
+type circuit struct {
+	mu        sync.Mutex
+	name      string
+	state     State
+	start     time.Time
+	requests  int
+	failures  int
+	until     time.Time
+	probes    int
+	successes int
+}
+
+// allow admits the operation, or returns an `*OpenError` if the circuit rejects it.
+func (c *circuit) allow(op *middleware.Operation, cfg Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == Open && !time.Now().Before(c.until) {
+		c.state, c.probes, c.successes = HalfOpen, 0, 0
+	}
+
+	switch c.state {
+	case Open:
+		return &OpenError{Operation: op.Name, Circuit: c.name, Until: c.until}
+	case HalfOpen:
+		if c.probes >= cfg.HalfOpenRequests {
+			return &OpenError{Operation: op.Name, Circuit: c.name, Until: c.until}
+		}
+		c.probes++
+	}
+
+	return nil
+}
+
+// done records the outcome of an admitted operation.
+func (c *circuit) done(failed bool, cfg Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	switch c.state {
+	case HalfOpen:
+		if failed {
+			c.trip(now, cfg.CoolDown)
+			return
+		}
+
+		c.successes++
+		if c.successes >= cfg.HalfOpenRequests {
+			c.state, c.start, c.requests, c.failures = Closed, now, 0, 0
+		}
+	case Closed:
+		if now.Sub(c.start) >= cfg.Window {
+			c.start, c.requests, c.failures = now, 0, 0
+		}
+
+		c.requests++
+		if failed {
+			c.failures++
+		}
+
+		if c.requests >= cfg.MinRequests && float64(c.failures)/float64(c.requests) >= cfg.FailureRate {
+			c.trip(now, cfg.CoolDown)
+		}
+	}
+}
+
+// release gives back the probe of an admitted operation whose outcome is neutral.
+func (c *circuit) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == HalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+// trip opens the circuit for the cool-down period.
+func (c *circuit) trip(now time.Time, coolDown time.Duration) {
+	c.state, c.until = Open, now.Add(coolDown)
+	c.start, c.requests, c.failures = now, 0, 0
+}
@@ -0,0 +1,149 @@
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/pkg/breaker"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/retry"
+)
+
+func Test_Breaker(t *testing.T) {
+	b := breaker.New(breaker.Config{
+		Scope:       breaker.Bucket,
+		MinRequests: 2,
+		CoolDown:    50 * time.Millisecond,
+	})
+
+	failing := errors.New("connection refused")
+	outcome := failing
+	handler := b.Middleware()(func(ctx context.Context, op *middleware.Operation) error {
+		return outcome
+	})
+	get := func(bucket string) error {
+		return handler(context.Background(), &middleware.Operation{Name: middleware.GetObject, Bucket: bucket, Key: "logo.png"})
+	}
+
+	get("assets")
+	get("assets")
+	if state := b.State("assets"); state != breaker.Open {
+		t.Fatalf("expected circuit to be open, got %v", state)
+	}
+
+	var open *breaker.OpenError
+	if err := get("assets"); !errors.Is(err, errs.ErrCircuitOpen) || !errors.As(err, &open) || open.Circuit != "assets" {
+		t.Errorf("expected circuit open error - %v", err)
+	}
+	if state := b.State("backups"); state != breaker.Closed {
+		t.Errorf("expected other buckets to be unaffected, got %v", state)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	t.Run("failed probe reopens", func(t *testing.T) {
+		if err := get("assets"); err != failing {
+			t.Fatalf("expected probe to be sent - %v", err)
+		}
+		if state := b.State("assets"); state != breaker.Open {
+			t.Errorf("expected circuit to reopen, got %v", state)
+		}
+	})
+
+	time.Sleep(60 * time.Millisecond)
+
+	t.Run("successful probe closes", func(t *testing.T) {
+		outcome = nil
+		if err := get("assets"); err != nil {
+			t.Fatalf("expected probe to succeed - %v", err)
+		}
+		if state := b.State("assets"); state != breaker.Closed {
+			t.Errorf("expected circuit to close, got %v", state)
+		}
+	})
+}
+
+func Test_Failure(t *testing.T) {
+	cases := map[error]bool{
+		nil:                      false,
+		context.Canceled:         false,
+		errors.New("timeout"):    true,
+		context.DeadlineExceeded: false,
+		awserr.New(request.CanceledErrorCode, "canceled", nil):          false,
+		&errs.Error{Code: request.CanceledErrorCode}:                    false,
+		&errs.Error{Code: request.InvalidParameterErrCode}:              false,
+		&errs.Error{Code: request.ErrCodeSerialization, StatusCode: 0}:  false,
+		&errs.Error{StatusCode: 404, Kinds: []error{errs.ErrNotFound}}:  false,
+		&errs.Error{StatusCode: 503, Kinds: []error{errs.ErrThrottled}}: true,
+		&errs.Error{StatusCode: 500}:                                    true,
+	}
+
+	for err, expected := range cases {
+		if breaker.Failure(err) != expected {
+			t.Errorf("unexpected failure classification - %v", err)
+		}
+	}
+}
+
+func Test_Client(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<Error><Code>InternalError</Code></Error>"))
+	})
+
+	client, _ := mock.Connect(t, handler,
+		sthree.WithRetry(retry.Policy{MaxAttempts: 1}),
+		sthree.WithCircuitBreaker(breaker.Config{MinRequests: 3}),
+	)
+
+	for i := 0; i < 5; i++ {
+		client.Bucket("assets").Get("logo.png")
+	}
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("expected circuit to open after 3 failures, got %v requests", n)
+	}
+
+	if _, err := client.Buckets.List(); !errors.Is(err, errs.ErrCircuitOpen) {
+		t.Errorf("expected circuit open error - %v", err)
+	}
+}
+
+func Test_Client_Canceled(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	client, _ := mock.Connect(t, handler,
+		sthree.WithRetry(retry.Policy{MaxAttempts: 1}),
+		sthree.WithCircuitBreaker(breaker.Config{MinRequests: 2}),
+	)
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		client.Bucket("assets").GetWithContext(ctx, "logo.png")
+		cancel()
+	}
+
+	if _, err := client.Bucket("assets").Get("logo.png"); errors.Is(err, errs.ErrCircuitOpen) {
+		t.Errorf("expected timeouts of the caller not to open the circuit - %v", err)
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("expected every operation to be sent, got %v requests", n)
+	}
+}
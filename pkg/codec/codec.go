@@ -0,0 +1,120 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec encodes the values stored as object bodies, and decodes them back.
+type Codec interface {
+	// Marshal encodes a value.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes data into the value pointed to by v.
+	Unmarshal(data []byte, v any) error
+
+	// ContentType is the MIME type of the encoded values (e.g., "application/json").
+	ContentType() string
+}
+
+// Codecs shipped with sthree, registered under their content types.
+var (
+	// JSON encodes values with `encoding/json`. It is the default codec of `Put`.
+	JSON Codec = &codec{"application/json", json.Marshal, json.Unmarshal}
+
+	// Gob encodes values with `encoding/gob`, which only Go programs can decode.
+	Gob Codec = &codec{"application/x-gob", gobMarshal, gobUnmarshal}
+
+	// YAML encodes values with `gopkg.in/yaml.v3`.
+	YAML Codec = &codec{"application/yaml", yaml.Marshal, yaml.Unmarshal}
+
+	// CBOR encodes values in the Concise Binary Object Representation (RFC 8949).
+	CBOR Codec = &codec{"application/cbor", cbor.Marshal, cbor.Unmarshal}
+
+	// MessagePack encodes values in the MessagePack format.
+	MessagePack Codec = &codec{"application/msgpack", msgpack.Marshal, msgpack.Unmarshal}
+)
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Codec{
+		JSON.ContentType():        JSON,
+		Gob.ContentType():         Gob,
+		YAML.ContentType():        YAML,
+		"application/x-yaml":      YAML,
+		"text/yaml":               YAML,
+		CBOR.ContentType():        CBOR,
+		MessagePack.ContentType(): MessagePack,
+		"application/x-msgpack":   MessagePack,
+	}
+)
+
+// Register registers a codec under its content type, replacing the codec registered for it, if any.
+//
+// @param c The codec to register.
+func Register(c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	registry[normalize(c.ContentType())] = c
+}
+
+// ForContentType returns the codec registered for a content type. Parameters of the
+// content type, such as the charset, are ignored.
+//
+// @param contentType The content type of an object (e.g., "application/json; charset=utf-8").
+// @return The codec, and whether one is registered for the content type.
+func ForContentType(contentType string) (Codec, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	c, ok := registry[normalize(contentType)]
+	return c, ok
+}
+
+func normalize(contentType string) string {
+	if media, _, err := mime.ParseMediaType(contentType); err == nil {
+		return media
+	}
+
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+type codec struct {
+	contentType string
+	marshal     func(v any) ([]byte, error)
+	unmarshal   func(data []byte, v any) error
+}
+
+func (c *codec) Marshal(v any) ([]byte, error) {
+	return c.marshal(v)
+}
+
+func (c *codec) Unmarshal(data []byte, v any) error {
+	return c.unmarshal(data, v)
+}
+
+func (c *codec) ContentType() string {
+	return c.contentType
+}
+
+func gobMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gobUnmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
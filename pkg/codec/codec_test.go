@@ -0,0 +1,89 @@
+package codec_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/codec"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+type manifest struct {
+	Name    string
+	Version int
+	Files   []string
+}
+
+func Test_Codecs(t *testing.T) {
+	expected := manifest{Name: "site", Version: 3, Files: []string{"index.html", "logo.png"}}
+
+	for _, c := range []codec.Codec{codec.JSON, codec.Gob, codec.YAML, codec.CBOR, codec.MessagePack} {
+		data, err := c.Marshal(expected)
+		if err != nil {
+			t.Errorf("failed to marshal with %v - %v", c.ContentType(), err.Error())
+			continue
+		}
+
+		value := manifest{}
+		if err := c.Unmarshal(data, &value); err != nil {
+			t.Errorf("failed to unmarshal with %v - %v", c.ContentType(), err.Error())
+			continue
+		}
+		if value.Name != expected.Name || value.Version != expected.Version || len(value.Files) != 2 {
+			t.Errorf("unexpected value decoded with %v - %+v", c.ContentType(), value)
+		}
+
+		if found, ok := codec.ForContentType(c.ContentType() + "; charset=utf-8"); !ok || found != c {
+			t.Errorf("codec not registered for %v", c.ContentType())
+		}
+	}
+
+	if _, ok := codec.ForContentType("image/png"); ok {
+		t.Errorf("expected no codec for image/png")
+	}
+}
+
+func Test_Put_Codec(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	bucket := client.Bucket("assets")
+	bucket.Codec = codec.YAML
+
+	expected := manifest{Name: "site", Version: 3}
+	info, err := bucket.Put("manifest.yaml", expected)
+	if err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if info.ContentType != "application/yaml" {
+		t.Errorf("unexpected content type %v", info.ContentType)
+	}
+
+	if _, err := bucket.Put("manifest.cbor", expected, objects.Put{Codec: codec.CBOR}); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+
+	for _, key := range []string{"manifest.yaml", "manifest.cbor"} {
+		object, err := bucket.Get(key)
+		if err != nil {
+			t.Fatalf("failed to get object - %v", err.Error())
+		}
+
+		value := manifest{}
+		if err := object.Decode(&value); err != nil {
+			t.Errorf("failed to decode %v - %v", key, err.Error())
+		}
+		if value.Name != expected.Name || value.Version != expected.Version {
+			t.Errorf("unexpected value decoded from %v - %+v", key, value)
+		}
+	}
+
+	if _, err := bucket.Put("invalid", func() {}, objects.Put{Codec: codec.JSON}); !errors.As(err, new(*errs.Error)) {
+		t.Errorf("expected marshal error, got %v", err)
+	}
+}
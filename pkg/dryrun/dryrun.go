@@ -0,0 +1,191 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// outputs creates the empty output returned for each mutating operation (see `middleware.Mutating`).
+var outputs = map[string]func() any{
+	middleware.PutObject:     func() any { return &s3.PutObjectOutput{} },
+	middleware.DeleteObject:  func() any { return &s3.DeleteObjectOutput{} },
+	middleware.Upload:        func() any { return &s3manager.UploadOutput{} },
+	middleware.CreateBucket:  func() any { return &s3.CreateBucketOutput{} },
+	middleware.DeleteBucket:  func() any { return &s3.DeleteBucketOutput{} },
+	middleware.PutBucketCors: func() any { return &s3.PutBucketCorsOutput{} },
+}
+
+// Step is a mutating operation recorded instead of being sent.
+type Step struct {
+	// Operation is the name of the operation (e.g., "PutObject").
+	Operation string `json:"operation"`
+
+	// Bucket is the bucket targeted by the operation, if any.
+	Bucket string `json:"bucket,omitempty"`
+
+	// Key is the object key targeted by the operation, if any.
+	Key string `json:"key,omitempty"`
+
+	// Size is the size of the body that would have been sent, or -1 if it cannot be known
+	// without reading it.
+	Size int64 `json:"size,omitempty"`
+
+	// Input is a copy of the fully built SDK input of the operation (e.g., *s3.PutObjectInput),
+	// with its secrets, such as SSE-C keys, blanked (see `Redact`). Its body is never read, so
+	// it serializes as an empty object (`"Body":{}`).
+	Input any `json:"input"`
+
+	// Time is when the operation was recorded.
+	Time time.Time `json:"time"`
+}
+
+// Plan records the mutating operations of a client in dry-run mode, in the order they were issued.
+// A Plan is safe for concurrent use, and serializes to JSON as the list of its steps.
+type Plan struct {
+	mu    sync.Mutex
+	steps []Step
+}
+
+// New creates an empty plan.
+//
+// @return A pointer to a new `Plan`.
+func New() *Plan {
+	return &Plan{}
+}
+
+// Steps returns a copy of the recorded steps.
+//
+// @return The steps, in the order they were recorded.
+func (p *Plan) Steps() []Step {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Step{}, p.steps...)
+}
+
+// Reset discards the recorded steps.
+func (p *Plan) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.steps = nil
+}
+
+// MarshalJSON serializes the plan as the list of its steps.
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Steps())
+}
+
+// Middleware returns a middleware that validates mutating operations and records them
+// in the plan instead of sending them. Read operations are sent as usual.
+//
+// Recorded operations succeed with an empty output, and invalid ones fail with the
+// validation error of the SDK, as they would have before reaching S3.
+//
+// @return A middleware recording every mutating operation.
+func (p *Plan) Middleware() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, op *middleware.Operation) error {
+			output, ok := outputs[op.Name]
+			if !ok {
+				return next(ctx, op)
+			}
+
+			if input, ok := op.Input.(interface{ Validate() error }); ok {
+				if err := input.Validate(); err != nil {
+					return errs.Wrap(op.Name, op.Bucket, op.Key, err)
+				}
+			}
+
+			p.mu.Lock()
+			p.steps = append(p.steps, Step{
+				Operation: op.Name,
+				Bucket:    op.Bucket,
+				Key:       op.Key,
+				Size:      size(op.Input),
+				Input:     Redact(op.Input),
+				Time:      time.Now(),
+			})
+			p.mu.Unlock()
+
+			// Unsent requests already carry their output.
+			if op.Output == nil {
+				op.Output = output()
+			}
+
+			return nil
+		}
+	}
+}
+
+// size returns the size of the body of an input, without consuming it.
+func size(input any) int64 {
+	var body io.Reader
+	switch input := input.(type) {
+	case *s3.PutObjectInput:
+		body = input.Body
+	case *s3manager.UploadInput:
+		body = input.Body
+	}
+	if body == nil {
+		return 0
+	}
+
+	seeker, ok := body.(io.Seeker)
+	if !ok {
+		return -1
+	}
+
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1
+	}
+	if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+		return -1
+	}
+
+	return end - current
+}
+
+// Redact returns a copy of an SDK input with every secret blanked: the fields tagged
+// `sensitive:"true"` by the SDK and the SSE-C key fields, including the MD5 digest of the key.
+// Inputs other than pointers to structs are returned as is. The body, if any, is shared.
+//
+// @param input The SDK input (e.g., *s3.PutObjectInput).
+// @return A redacted copy of the input.
+func Redact(input any) any {
+	value := reflect.ValueOf(input)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return input
+	}
+
+	clone := reflect.New(value.Elem().Type())
+	clone.Elem().Set(value.Elem())
+
+	fields := clone.Elem().Type()
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		if field.Tag.Get("sensitive") == "true" || strings.HasPrefix(field.Name, "SSECustomerKey") {
+			if target := clone.Elem().Field(i); target.CanSet() {
+				target.SetZero()
+			}
+		}
+	}
+
+	return clone.Interface()
+}
@@ -0,0 +1,95 @@
+package dryrun_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+func Test_DryRun(t *testing.T) {
+	client, server := mock.Client(t, sthree.WithDryRun())
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Errorf("failed to create bucket - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Put("logo.txt", []byte("logo")); err != nil {
+		t.Errorf("failed to put object - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Delete("old.txt"); err != nil {
+		t.Errorf("failed to delete object - %v", err.Error())
+	}
+
+	req, _ := client.Requests.PutObject(objects.Put{Bucket: "assets", Key: "icon.txt", Body: []byte("icon")})
+	if err := req.Send(); err != nil {
+		t.Errorf("failed to send request - %v", err.Error())
+	}
+
+	if requests := server.Requests(); len(requests) != 0 {
+		t.Errorf("expected no request to be sent, got %v", requests)
+	}
+
+	steps := client.Plan.Steps()
+	expected := []string{middleware.CreateBucket, middleware.PutObject, middleware.DeleteObject, middleware.PutObject}
+	if len(steps) != len(expected) {
+		t.Fatalf("expected %v steps, got %+v", len(expected), steps)
+	}
+	for i, step := range steps {
+		if step.Operation != expected[i] || step.Bucket != "assets" || step.Input == nil {
+			t.Errorf("unexpected step - %+v", step)
+		}
+	}
+	if steps[1].Key != "logo.txt" || steps[1].Size == 0 {
+		t.Errorf("unexpected put step - %+v", steps[1])
+	}
+
+	if _, err := client.Bucket("assets").Put("", "logo"); !errors.As(err, new(*errs.Error)) {
+		t.Errorf("expected invalid put to fail validation, got %v", err)
+	}
+	if len(client.Plan.Steps()) != len(expected) {
+		t.Errorf("expected invalid put not to be recorded")
+	}
+
+	data, err := json.Marshal(client.Plan)
+	if err != nil {
+		t.Fatalf("failed to marshal plan - %v", err.Error())
+	}
+
+	decoded := []map[string]any{}
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded) != len(expected) {
+		t.Errorf("unexpected plan json - %s", data)
+	}
+
+	client.Plan.Reset()
+
+	secret := "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	sse := objects.ObjectDetails{SSECustomerAlgorithm: "AES256", SSECustomerKey: secret, SSECustomerKeyMD5: "a2V5LWRpZ2VzdA=="}
+	if _, err := client.Bucket("assets").Put("secret.txt", "secret", objects.Put{Config: sse}); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+
+	data, err = json.Marshal(client.Plan)
+	if err != nil {
+		t.Fatalf("failed to marshal plan - %v", err.Error())
+	}
+	if strings.Contains(string(data), secret) || strings.Contains(string(data), sse.SSECustomerKeyMD5) {
+		t.Errorf("expected the SSE-C key to be redacted - %s", data)
+	}
+	if !strings.Contains(string(data), "AES256") {
+		t.Errorf("expected the SSE-C algorithm to be kept - %s", data)
+	}
+	if !strings.Contains(string(data), `"Body":{}`) || strings.Contains(string(data), `"secret"`) {
+		t.Errorf("expected the body not to be serialized - %s", data)
+	}
+
+	client.Plan.Reset()
+	if len(client.Plan.Steps()) != 0 {
+		t.Errorf("expected plan to be empty after reset")
+	}
+}
@@ -0,0 +1,218 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// Sentinel errors matched with `errors.Is` against the errors returned by the sthree modules.
+var (
+	// ErrNotFound is returned when the object, version or upload does not exist.
+	// It also matches ErrBucketNotFound errors.
+	ErrNotFound = errors.New("sthree: not found")
+
+	// ErrBucketNotFound is returned when the bucket does not exist.
+	ErrBucketNotFound = errors.New("sthree: bucket not found")
+
+	// ErrAccessDenied is returned when the credentials are not allowed to perform the operation.
+	ErrAccessDenied = errors.New("sthree: access denied")
+
+	// ErrPreconditionFailed is returned when a conditional request's precondition does not hold.
+	ErrPreconditionFailed = errors.New("sthree: precondition failed")
+
+	// ErrThrottled is returned when S3 asks the caller to slow down.
+	ErrThrottled = errors.New("sthree: throttled")
+
+	// ErrAlreadyExists is returned when creating a bucket that already exists.
+	ErrAlreadyExists = errors.New("sthree: already exists")
+
+	// ErrCircuitOpen is returned, without contacting S3, while the circuit breaker of the client is open.
+	ErrCircuitOpen = errors.New("sthree: circuit open")
+
+	// ErrProtected is returned, without contacting S3, for operations rejected by the safety policy of the client.
+	ErrProtected = errors.New("sthree: protected")
+
+	// ErrReadOnly is returned, without contacting S3, for mutating operations of a read-only client.
+	// It also matches ErrProtected.
+	ErrReadOnly = errors.New("sthree: read-only")
+
+	// ErrMismatch is returned when a downloaded range does not match the size or ETag of the object,
+	// such as when the object is replaced during a download.
+	ErrMismatch = errors.New("sthree: mismatch")
+)
+
+// kinds maps AWS error codes to the sentinels they match.
+var kinds = map[string][]error{
+	"NoSuchKey":               {ErrNotFound},
+	"NoSuchVersion":           {ErrNotFound},
+	"NoSuchUpload":            {ErrNotFound},
+	"NotFound":                {ErrNotFound},
+	"NoSuchBucket":            {ErrBucketNotFound, ErrNotFound},
+	"AccessDenied":            {ErrAccessDenied},
+	"AllAccessDisabled":       {ErrAccessDenied},
+	"InvalidAccessKeyId":      {ErrAccessDenied},
+	"SignatureDoesNotMatch":   {ErrAccessDenied},
+	"Forbidden":               {ErrAccessDenied},
+	"PreconditionFailed":      {ErrPreconditionFailed},
+	"SlowDown":                {ErrThrottled},
+	"Throttling":              {ErrThrottled},
+	"ThrottlingException":     {ErrThrottled},
+	"RequestLimitExceeded":    {ErrThrottled},
+	"TooManyRequests":         {ErrThrottled},
+	"BucketAlreadyExists":     {ErrAlreadyExists},
+	"BucketAlreadyOwnedByYou": {ErrAlreadyExists},
+}
+
+// statuses maps HTTP status codes to the sentinels they match when the error code is unknown.
+var statuses = map[int][]error{
+	http.StatusNotFound:           {ErrNotFound},
+	http.StatusForbidden:          {ErrAccessDenied},
+	http.StatusPreconditionFailed: {ErrPreconditionFailed},
+	http.StatusTooManyRequests:    {ErrThrottled},
+}
+
+// Error is the error returned by the sthree modules for failed S3 operations.
+//
+// It matches the sentinel errors of its kind through `errors.Is`, and still wraps the
+// original SDK error, so `errors.As` can retrieve an `awserr.Error` from it.
+type Error struct {
+	// Operation is the name of the failed S3 operation (e.g., "GetObject").
+	Operation string
+
+	// Bucket is the bucket targeted by the operation, if any.
+	Bucket string
+
+	// Key is the object key targeted by the operation, if any.
+	Key string
+
+	// Code is the AWS error code (e.g., "NoSuchKey").
+	Code string
+
+	// Message is the AWS error message.
+	Message string
+
+	// StatusCode is the HTTP status code of the response, or zero if none was received.
+	StatusCode int
+
+	// RequestID is the AWS request ID of the response, if any.
+	RequestID string
+
+	// Kinds are the sentinel errors this error matches.
+	Kinds []error
+
+	// Err is the original error.
+	Err error
+}
+
+// Wrap converts an error returned by the AWS SDK into an `*Error`.
+//
+// Nil errors and errors that already are an `*Error` are returned as is.
+//
+// @param operation The name of the S3 operation that failed.
+// @param bucket The bucket targeted by the operation, if any.
+// @param key The object key targeted by the operation, if any.
+// @param err The error returned by the SDK.
+// @return An `*Error` wrapping err, or nil if err is nil.
+func Wrap(operation, bucket, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	e = &Error{
+		Operation: operation,
+		Bucket:    bucket,
+		Key:       key,
+		Message:   err.Error(),
+		Err:       err,
+	}
+
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		e.Code = aerr.Code()
+		e.Message = aerr.Message()
+	}
+
+	var rerr awserr.RequestFailure
+	if errors.As(err, &rerr) {
+		e.StatusCode = rerr.StatusCode()
+		e.RequestID = rerr.RequestID()
+	}
+
+	e.Kinds = kinds[e.Code]
+	if e.Kinds == nil {
+		e.Kinds = statuses[e.StatusCode]
+	}
+
+	// HEAD requests carry no error body, so a missing bucket is only reported as a 404.
+	if key == "" && bucket != "" && e.StatusCode == http.StatusNotFound {
+		e.Kinds = []error{ErrBucketNotFound, ErrNotFound}
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	target := strings.TrimSuffix(e.Bucket+"/"+e.Key, "/")
+
+	details := ""
+	if e.StatusCode != 0 {
+		details = fmt.Sprintf(" (status %d, request id %s)", e.StatusCode, e.RequestID)
+	}
+
+	if e.Code == "" {
+		return fmt.Sprintf("sthree: %s %s: %s%s", e.Operation, target, e.Message, details)
+	}
+
+	return fmt.Sprintf("sthree: %s %s: %s: %s%s", e.Operation, target, e.Code, e.Message, details)
+}
+
+// Is reports whether the error matches one of its sentinel kinds.
+func (e *Error) Is(target error) bool {
+	for _, kind := range e.Kinds {
+		if kind == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unwrap returns the original error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status code of a failed S3 operation, or zero if unknown.
+//
+// @param err The error returned by a sthree module.
+// @return The HTTP status code, or zero.
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+
+	return 0
+}
+
+// RequestID returns the AWS request ID of a failed S3 operation, or an empty string if unknown.
+//
+// @param err The error returned by a sthree module.
+// @return The AWS request ID, or an empty string.
+func RequestID(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.RequestID
+	}
+
+	return ""
+}
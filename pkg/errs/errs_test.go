@@ -0,0 +1,51 @@
+package errs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+func Test_Errors(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	t.Run("missing bucket", func(t *testing.T) {
+		_, err := client.Bucket("missing").Get("logo.png")
+
+		if !errors.Is(err, errs.ErrBucketNotFound) || !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("expected bucket not found error - %v", err)
+		}
+	})
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := client.Bucket("assets").Get("logo.png")
+
+		if !errors.Is(err, errs.ErrNotFound) || errors.Is(err, errs.ErrBucketNotFound) {
+			t.Errorf("expected not found error - %v", err)
+		}
+		if errs.StatusCode(err) != 404 || errs.RequestID(err) == "" {
+			t.Errorf("expected status code and request id - %v", err)
+		}
+
+		var aerr awserr.Error
+		if !errors.As(err, &aerr) || aerr.Code() != "NoSuchKey" {
+			t.Errorf("expected original sdk error to be wrapped - %v", err)
+		}
+	})
+
+	t.Run("existing bucket", func(t *testing.T) {
+		_, err := client.Buckets.Create("assets")
+
+		if !errors.Is(err, errs.ErrAlreadyExists) {
+			t.Errorf("expected already exists error - %v", err)
+		}
+	})
+}
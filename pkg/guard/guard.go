@@ -0,0 +1,147 @@
+package guard
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// Rules select buckets and object keys.
+type Rules struct {
+	// Buckets are bucket names, or `path.Match` patterns such as "prod-*".
+	Buckets []string `yaml:"buckets"`
+
+	// Prefixes are object key prefixes, matched in every bucket.
+	Prefixes []string `yaml:"prefixes"`
+}
+
+// Policy is the safety policy of a client, checked before every mutating operation is sent.
+//
+// Destructive operations (deleting or overwriting objects, deleting buckets and replacing their CORS
+// configuration) are rejected when they target a bucket or key matched by Deny, and, when Allow has
+// rules, when they target a bucket or key not matched by it. Bucket creations are only rejected in
+// read-only mode.
+type Policy struct {
+	// ReadOnly rejects every mutating operation.
+	ReadOnly bool `yaml:"read_only"`
+
+	// Allow, when not empty, lists the only buckets and key prefixes that can be deleted or overwritten.
+	Allow Rules `yaml:"allow"`
+
+	// Deny lists buckets and key prefixes that can never be deleted or overwritten.
+	Deny Rules `yaml:"deny"`
+}
+
+// RejectedError is returned, without contacting S3, for operations rejected by the policy.
+// It matches `errs.ErrProtected` through `errors.Is`, and also `errs.ErrReadOnly` in read-only mode.
+type RejectedError struct {
+	// Operation is the name of the rejected operation.
+	Operation string
+
+	// Bucket is the bucket targeted by the operation, if any.
+	Bucket string
+
+	// Key is the object key targeted by the operation, if any.
+	Key string
+
+	// Reason explains which rule rejected the operation.
+	Reason string
+
+	// ReadOnly reports whether the operation was rejected by the read-only mode.
+	ReadOnly bool
+}
+
+func (e *RejectedError) Error() string {
+	target := strings.TrimSuffix(e.Bucket+"/"+e.Key, "/")
+
+	return fmt.Sprintf("sthree: %s %s: rejected by safety policy: %s", e.Operation, target, e.Reason)
+}
+
+// Is reports whether the target is `errs.ErrProtected`, or `errs.ErrReadOnly` in read-only mode.
+func (e *RejectedError) Is(target error) bool {
+	return target == errs.ErrProtected || (e.ReadOnly && target == errs.ErrReadOnly)
+}
+
+// Check reports whether the policy lets an operation through.
+//
+// @param operation The name of the operation (e.g., "DeleteObject").
+// @param bucket The bucket targeted by the operation, if any.
+// @param key The object key targeted by the operation, if any.
+// @return A `*RejectedError` if the policy rejects the operation, or nil.
+func (p Policy) Check(operation, bucket, key string) error {
+	if !middleware.Mutating(operation) {
+		return nil
+	}
+
+	reject := func(readOnly bool, format string, args ...any) error {
+		return &RejectedError{
+			Operation: operation,
+			Bucket:    bucket,
+			Key:       key,
+			Reason:    fmt.Sprintf(format, args...),
+			ReadOnly:  readOnly,
+		}
+	}
+
+	if p.ReadOnly {
+		return reject(true, "client is read-only")
+	}
+	if operation == middleware.CreateBucket {
+		return nil
+	}
+
+	if pattern, ok := matchBucket(p.Deny.Buckets, bucket); ok {
+		return reject(false, "bucket is protected by %q", pattern)
+	}
+	if prefix, ok := matchKey(p.Deny.Prefixes, key); ok {
+		return reject(false, "key is protected by prefix %q", prefix)
+	}
+
+	if _, ok := matchBucket(p.Allow.Buckets, bucket); !ok && len(p.Allow.Buckets) > 0 {
+		return reject(false, "bucket is not in the allow list")
+	}
+	if _, ok := matchKey(p.Allow.Prefixes, key); !ok && len(p.Allow.Prefixes) > 0 && key != "" {
+		return reject(false, "key does not match an allowed prefix")
+	}
+
+	return nil
+}
+
+// Middleware returns a middleware that rejects the operations the policy does not let through.
+//
+// @return A middleware enforcing the policy.
+func (p Policy) Middleware() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, op *middleware.Operation) error {
+			if err := p.Check(op.Name, op.Bucket, op.Key); err != nil {
+				return err
+			}
+
+			return next(ctx, op)
+		}
+	}
+}
+
+func matchBucket(patterns []string, bucket string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, bucket); ok {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
+func matchKey(prefixes []string, key string) (string, bool) {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return prefix, true
+		}
+	}
+
+	return "", false
+}
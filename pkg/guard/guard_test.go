@@ -0,0 +1,97 @@
+package guard_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/guard"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+func Test_Policy(t *testing.T) {
+	policy := guard.Policy{
+		Allow: guard.Rules{Buckets: []string{"staging-*", "prod-logs"}},
+		Deny:  guard.Rules{Buckets: []string{"prod-logs"}, Prefixes: []string{"backups/"}},
+	}
+
+	cases := []struct {
+		operation, bucket, key string
+		allowed                bool
+	}{
+		{middleware.GetObject, "prod-data", "report.csv", true},
+		{middleware.CreateBucket, "prod-data", "", true},
+		{middleware.DeleteObject, "staging-assets", "logo.png", true},
+		{middleware.DeleteBucket, "staging-assets", "", true},
+		{middleware.DeleteObject, "prod-data", "logo.png", false},
+		{middleware.DeleteBucket, "prod-logs", "", false},
+		{middleware.PutObject, "staging-assets", "backups/2024.tar", false},
+	}
+
+	for _, c := range cases {
+		err := policy.Check(c.operation, c.bucket, c.key)
+		if c.allowed && err != nil {
+			t.Errorf("expected %v %v/%v to be allowed - %v", c.operation, c.bucket, c.key, err.Error())
+		}
+		if !c.allowed && !errors.Is(err, errs.ErrProtected) {
+			t.Errorf("expected %v %v/%v to be protected, got %v", c.operation, c.bucket, c.key, err)
+		}
+	}
+
+	readOnly := guard.Policy{ReadOnly: true}
+	if err := readOnly.Check(middleware.CreateBucket, "staging-assets", ""); !errors.Is(err, errs.ErrReadOnly) {
+		t.Errorf("expected read-only error, got %v", err)
+	}
+	if err := readOnly.Check(middleware.ListObjects, "staging-assets", ""); err != nil {
+		t.Errorf("expected reads to be allowed - %v", err.Error())
+	}
+}
+
+func Test_Guard(t *testing.T) {
+	server := mock.Start(t)
+
+	open := func(opts ...sthree.Option) (*sthree.Sthree, error) {
+		return sthree.Open(session.Must(session.NewSession()), append(mock.Options(server.URL), opts...)...)
+	}
+
+	client, err := open(sthree.WithGuard(guard.Policy{Deny: guard.Rules{Buckets: []string{"prod-*"}}}))
+	if err != nil {
+		t.Fatalf("failed to open client - %v", err.Error())
+	}
+
+	if _, err := client.Buckets.Create("prod-data"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	sent := len(server.Requests())
+
+	if _, err := client.Buckets.Delete("prod-data"); !errors.Is(err, errs.ErrProtected) {
+		t.Errorf("expected bucket deletion to be rejected, got %v", err)
+	}
+	if _, err := client.Bucket("prod-data").Delete("logo.png"); !errors.Is(err, errs.ErrProtected) {
+		t.Errorf("expected object deletion to be rejected, got %v", err)
+	}
+	if requests := server.Requests(); len(requests) != sent {
+		t.Errorf("expected rejected operations not to be sent - %v", requests[sent:])
+	}
+
+	readOnly, err := open(sthree.WithReadOnly())
+	if err != nil {
+		t.Fatalf("failed to open client - %v", err.Error())
+	}
+
+	if _, err := readOnly.Bucket("prod-data").Put("logo.png", "logo"); !errors.Is(err, errs.ErrReadOnly) {
+		t.Errorf("expected put to be rejected, got %v", err)
+	}
+	if _, err := readOnly.Bucket("prod-data").List(); err != nil {
+		t.Errorf("failed to list objects - %v", err.Error())
+	}
+
+	_, err = open(sthree.WithGuard(guard.Policy{Deny: guard.Rules{Buckets: []string{"prod-["}, Prefixes: []string{""}}}))
+	if !errors.As(err, new(*sthree.ConfigError)) {
+		t.Errorf("expected invalid policy to be rejected, got %v", err)
+	}
+}
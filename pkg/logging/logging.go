@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// Options tunes the records emitted for S3 operations.
+type Options struct {
+	// Level is the level of records for successful operations.
+	// The zero value logs them at slog.LevelInfo.
+	Level slog.Level `yaml:"level"`
+
+	// ErrorLevel is the level of records for failed operations.
+	// When nil, they are logged at slog.LevelError.
+	ErrorLevel *slog.Level `yaml:"error_level"`
+
+	// Input adds the (redacted) SDK input of each operation to its record.
+	Input bool `yaml:"input"`
+}
+
+// patterns match the secrets that must never reach the logs:
+// SSE-C keys sent as headers and the signatures of signed or presigned requests.
+var patterns = []struct {
+	re   *regexp.Regexp
+	with string
+}{
+	{regexp.MustCompile(`(?i)(x-amz-(?:copy-source-)?server-side-encryption-customer-key:\s*)[^\s]+`), "${1}<redacted>"},
+	{regexp.MustCompile(`(?i)(x-amz-signature=)[0-9a-f]+`), "${1}<redacted>"},
+	{regexp.MustCompile(`(?i)(x-amz-security-token=)[^&\s]+`), "${1}<redacted>"},
+	{regexp.MustCompile(`(Signature=)[0-9a-f]+`), "${1}<redacted>"},
+}
+
+// Redact hides SSE-C keys, request signatures and security tokens found in the given text.
+//
+// @param s The text to redact (e.g., a dumped HTTP request or a presigned URL).
+// @return The text with every secret replaced by "<redacted>".
+func Redact(s string) string {
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.with)
+	}
+
+	return s
+}
+
+// Middleware logs every operation that runs through the chain.
+//
+// Each record carries the operation, bucket, key, HTTP status code, AWS request ID,
+// retries and latency, plus the error of failed operations.
+//
+// @param logger The logger records are written to.
+// @param opts The levels and contents of the records.
+// @return A middleware that logs operations.
+func Middleware(logger *slog.Logger, opts Options) middleware.Middleware {
+	errorLevel := slog.LevelError
+	if opts.ErrorLevel != nil {
+		errorLevel = *opts.ErrorLevel
+	}
+
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, op *middleware.Operation) error {
+			err := next(ctx, op)
+
+			level := opts.Level
+			if err != nil {
+				level = errorLevel
+			}
+			if !logger.Enabled(ctx, level) {
+				return err
+			}
+
+			attrs := []slog.Attr{
+				slog.String("operation", op.Name),
+				slog.String("bucket", op.Bucket),
+				slog.String("key", op.Key),
+				slog.Int("status", op.StatusCode),
+				slog.String("request_id", op.RequestID),
+				slog.Int("retries", op.Retries),
+				slog.Duration("latency", op.Duration),
+			}
+			if opts.Input && op.Input != nil {
+				attrs = append(attrs, slog.String("input", Redact(fmt.Sprint(op.Input))))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", Redact(err.Error())))
+			}
+
+			logger.LogAttrs(ctx, level, "s3 operation", attrs...)
+
+			return err
+		}
+	}
+}
+
+// SDKLogger adapts a slog.Logger into an `aws.Logger`, so the raw debug output
+// of the AWS SDK is written as debug records with every secret redacted.
+//
+// @param logger The logger records are written to.
+// @return An `aws.Logger` for `aws.Config.Logger`.
+func SDKLogger(logger *slog.Logger) aws.Logger {
+	return aws.LoggerFunc(func(args ...interface{}) {
+		logger.Debug(Redact(fmt.Sprint(args...)), slog.String("source", "aws-sdk"))
+	})
+}
@@ -0,0 +1,64 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/avila-r/sthree/pkg/logging"
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+func Test_Redact(t *testing.T) {
+	cases := map[string]string{
+		"X-Amz-Server-Side-Encryption-Customer-Key: c2VjcmV0a2V5\r\n":       "X-Amz-Server-Side-Encryption-Customer-Key: <redacted>\r\n",
+		"https://b.s3.amazonaws.com/k?X-Amz-Signature=abc123&X-Amz-Date=1":  "https://b.s3.amazonaws.com/k?X-Amz-Signature=<redacted>&X-Amz-Date=1",
+		"Authorization: AWS4-HMAC-SHA256 Credential=id, Signature=deadbeef": "Authorization: AWS4-HMAC-SHA256 Credential=id, Signature=<redacted>",
+	}
+
+	for input, expected := range cases {
+		if redacted := logging.Redact(input); redacted != expected {
+			t.Errorf("unexpected redaction - %q", redacted)
+		}
+	}
+}
+
+func Test_Middleware(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buffer, nil))
+
+	chain := &middleware.Chain{}
+	chain.Use(logging.Middleware(logger, logging.Options{}))
+
+	op := &middleware.Operation{Name: middleware.GetObject, Bucket: "assets", Key: "logo.png"}
+	chain.Do(context.Background(), op, func(ctx context.Context, op *middleware.Operation) error {
+		op.StatusCode = 404
+		op.RequestID = "req-1"
+		return errors.New("NoSuchKey")
+	})
+
+	record := buffer.String()
+	for _, attr := range []string{"level=ERROR", "operation=GetObject", "bucket=assets", "key=logo.png", "status=404", "request_id=req-1", "retries=0", "latency=", "error=NoSuchKey"} {
+		if !strings.Contains(record, attr) {
+			t.Errorf("record is missing %q - %v", attr, record)
+		}
+	}
+
+	t.Run("failures can be logged at the info level", func(t *testing.T) {
+		buffer.Reset()
+		level := slog.LevelInfo
+
+		chain := &middleware.Chain{}
+		chain.Use(logging.Middleware(logger, logging.Options{ErrorLevel: &level}))
+		chain.Do(context.Background(), op, func(ctx context.Context, op *middleware.Operation) error {
+			return errors.New("NoSuchKey")
+		})
+
+		if record := buffer.String(); !strings.Contains(record, "level=INFO") || !strings.Contains(record, "error=NoSuchKey") {
+			t.Errorf("expected failure to be logged at the info level - %v", record)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// DefaultBuckets are the default upper bounds, in seconds, of the latency histogram buckets.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Names of the metrics exposed by a Collector.
+const (
+	Requests      = "sthree_requests_total"
+	Latency       = "sthree_request_duration_seconds"
+	BytesSent     = "sthree_bytes_sent_total"
+	BytesReceived = "sthree_bytes_received_total"
+)
+
+// Collector counts the S3 operations flowing through the clients it is installed on,
+// and renders them in the Prometheus text exposition format.
+// A Collector is safe for concurrent use, and can be shared by several clients.
+type Collector struct {
+	mu       sync.Mutex
+	buckets  []float64
+	requests map[series]uint64
+	latency  map[series]*histogram
+	sent     map[series]uint64
+	received map[series]uint64
+}
+
+// series identifies a time series by its labels.
+type series struct {
+	operation string
+	bucket    string
+	status    string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// New creates an empty collector.
+//
+// @param buckets Optional upper bounds, in seconds, of the latency histogram buckets; defaults to `DefaultBuckets`.
+// @return A pointer to a new `Collector`.
+func New(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+
+	return &Collector{
+		buckets:  buckets,
+		requests: map[series]uint64{},
+		latency:  map[series]*histogram{},
+		sent:     map[series]uint64{},
+		received: map[series]uint64{},
+	}
+}
+
+// Middleware returns a middleware that records every operation in the collector.
+//
+// @return A middleware observing the outcome of every operation.
+func (c *Collector) Middleware() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, op *middleware.Operation) error {
+			err := next(ctx, op)
+			c.Observe(op)
+
+			return err
+		}
+	}
+}
+
+// Observe records a completed operation. Nested operations (see `middleware.Nested`) only record
+// their latency, since each of their requests is counted, with its bytes, as an operation of its own.
+//
+// @param op The completed operation.
+func (c *Collector) Observe(op *middleware.Operation) {
+	nested := middleware.Nested(op.Name)
+
+	status := "none"
+	if op.StatusCode != 0 {
+		status = strconv.Itoa(op.StatusCode)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !nested {
+		c.requests[series{operation: op.Name, bucket: op.Bucket, status: status}]++
+	}
+
+	s := series{operation: op.Name, bucket: op.Bucket}
+	h, ok := c.latency[s]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.latency[s] = h
+	}
+
+	seconds := op.Duration.Seconds()
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+
+	if nested {
+		return
+	}
+	if op.BytesSent > 0 {
+		c.sent[s] += uint64(op.BytesSent)
+	}
+	if op.BytesReceived > 0 {
+		c.received[s] += uint64(op.BytesReceived)
+	}
+}
+
+// Count returns the number of recorded operations matching the given labels.
+//
+// @param operation The name of the operation (e.g., "GetObject").
+// @param bucket The bucket, or an empty string for every bucket.
+// @return The number of operations recorded.
+func (c *Collector) Count(operation, bucket string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := uint64(0)
+	for s, n := range c.requests {
+		if s.operation == operation && (bucket == "" || s.bucket == bucket) {
+			total += n
+		}
+	}
+
+	return total
+}
+
+// WriteTo writes every metric in the Prometheus text exposition format.
+//
+// @param w The writer the metrics are written to.
+// @return The number of bytes written, and the first write error encountered.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "# HELP %s Number of S3 operations by operation, bucket and HTTP status code.\n", Requests)
+	fmt.Fprintf(b, "# TYPE %s counter\n", Requests)
+	for _, s := range sorted(c.requests) {
+		fmt.Fprintf(b, "%s{%s} %d\n", Requests, s.labels(), c.requests[s])
+	}
+
+	fmt.Fprintf(b, "# HELP %s Latency of S3 operations, retries included.\n", Latency)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", Latency)
+	for _, s := range sorted(c.latency) {
+		h := c.latency[s]
+		for i, bound := range c.buckets {
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", Latency, s.labels(), format(bound), h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", Latency, s.labels(), h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", Latency, s.labels(), format(h.sum))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", Latency, s.labels(), h.count)
+	}
+
+	fmt.Fprintf(b, "# HELP %s Bytes sent to S3 in request bodies.\n", BytesSent)
+	fmt.Fprintf(b, "# TYPE %s counter\n", BytesSent)
+	for _, s := range sorted(c.sent) {
+		fmt.Fprintf(b, "%s{%s} %d\n", BytesSent, s.labels(), c.sent[s])
+	}
+
+	fmt.Fprintf(b, "# HELP %s Bytes received from S3 in response bodies.\n", BytesReceived)
+	fmt.Fprintf(b, "# TYPE %s counter\n", BytesReceived)
+	for _, s := range sorted(c.received) {
+		fmt.Fprintf(b, "%s{%s} %d\n", BytesReceived, s.labels(), c.received[s])
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler returns an HTTP handler serving the metrics in the Prometheus text exposition format.
+//
+// @return An `http.Handler` to mount on a metrics endpoint (e.g., "/metrics").
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.WriteTo(w)
+	})
+}
+
+func (s series) labels() string {
+	labels := fmt.Sprintf("operation=\"%s\",bucket=\"%s\"", escape(s.operation), escape(s.bucket))
+	if s.status != "" {
+		labels += fmt.Sprintf(",status=\"%s\"", escape(s.status))
+	}
+
+	return labels
+}
+
+func sorted[V any](m map[series]V) []series {
+	keys := make([]series, 0, len(m))
+	for s := range m {
+		keys = append(keys, s)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.operation != b.operation {
+			return a.operation < b.operation
+		}
+		if a.bucket != b.bucket {
+			return a.bucket < b.bucket
+		}
+		return a.status < b.status
+	})
+
+	return keys
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(value string) string {
+	return escaper.Replace(value)
+}
+
+func format(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
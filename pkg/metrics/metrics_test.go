@@ -0,0 +1,106 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/metrics"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+func Test_Collector(t *testing.T) {
+	collector := metrics.New()
+	client, _ := mock.Client(t, sthree.WithMetrics(collector))
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Put("logo.png", "logo"); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	client.Bucket("assets").Get("logo.png")
+	client.Bucket("assets").Get("missing.png")
+
+	if n := collector.Count("GetObject", "assets"); n != 2 {
+		t.Errorf("expected 2 gets, got %v", n)
+	}
+	if n := collector.Count("PutObject", ""); n != 1 {
+		t.Errorf("expected 1 put, got %v", n)
+	}
+
+	recorder := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type - %v", ct)
+	}
+
+	body := recorder.Body.String()
+	expected := []string{
+		"# TYPE sthree_requests_total counter",
+		`sthree_requests_total{operation="GetObject",bucket="assets",status="200"} 1` + "\n",
+		`sthree_requests_total{operation="GetObject",bucket="assets",status="404"} 1` + "\n",
+		`sthree_requests_total{operation="PutObject",bucket="assets",status="200"} 1` + "\n",
+		"# TYPE sthree_request_duration_seconds histogram",
+		`sthree_request_duration_seconds_bucket{operation="GetObject",bucket="assets",le="+Inf"} 2` + "\n",
+		`sthree_request_duration_seconds_count{operation="PutObject",bucket="assets"} 1` + "\n",
+		`sthree_bytes_sent_total{operation="PutObject",bucket="assets"} 4` + "\n",
+		`sthree_bytes_received_total{operation="GetObject",bucket="assets"} `,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected metrics to contain %q - %v", line, body)
+		}
+	}
+}
+
+func Test_Collector_Download(t *testing.T) {
+	collector := metrics.New()
+	client, _ := mock.Client(t, sthree.WithMetrics(collector))
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Put("archive.bin", strings.Repeat("s", 3000)); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Download("archive.bin", &writerAt{}, objects.Download{PartSize: 1 << 10, Concurrency: 1}); err != nil {
+		t.Fatalf("failed to download object - %v", err.Error())
+	}
+
+	if n := collector.Count("GetObject", "assets"); n != 3 {
+		t.Errorf("expected a get per range, got %v", n)
+	}
+	if n := collector.Count("Download", "assets"); n != 0 {
+		t.Errorf("expected the download not to be counted as a request, got %v", n)
+	}
+
+	var body bytes.Buffer
+	collector.WriteTo(&body)
+	if !strings.Contains(body.String(), `sthree_bytes_received_total{operation="GetObject",bucket="assets"} 3000`+"\n") {
+		t.Errorf("expected the ranges to be received once - %v", body.String())
+	}
+	if strings.Contains(body.String(), `sthree_bytes_received_total{operation="Download"`) {
+		t.Errorf("expected the download bytes not to be counted twice - %v", body.String())
+	}
+	if !strings.Contains(body.String(), `sthree_request_duration_seconds_count{operation="Download",bucket="assets"} 1`) {
+		t.Errorf("expected the latency of the download to be recorded - %v", body.String())
+	}
+}
+
+// writerAt is an in-memory io.WriterAt, written by a single goroutine.
+type writerAt struct {
+	data []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+
+	return copy(w.data[off:], p), nil
+}
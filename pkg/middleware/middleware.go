@@ -0,0 +1,392 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/avila-r/sthree/pkg/errs"
+)
+
+// Names of the operations issued by the sthree modules.
+const (
+	GetObject     = "GetObject"
+	PutObject     = "PutObject"
+	DeleteObject  = "DeleteObject"
+	ListObjects   = "ListObjectsV2"
+	Upload        = "Upload"
+	Download      = "Download"
+	CreateBucket  = "CreateBucket"
+	DeleteBucket  = "DeleteBucket"
+	ListBuckets   = "ListBuckets"
+	HeadBucket    = "HeadBucket"
+	PutBucketCors = "PutBucketCors"
+)
+
+// mutating lists the operations that change the state of S3.
+var mutating = map[string]bool{
+	PutObject:     true,
+	DeleteObject:  true,
+	Upload:        true,
+	CreateBucket:  true,
+	DeleteBucket:  true,
+	PutBucketCors: true,
+}
+
+// Mutating reports whether an operation changes the state of S3, as opposed to only reading it.
+//
+// @param operation The name of the operation (e.g., "PutObject").
+// @return Whether the operation is mutating.
+func Mutating(operation string) bool {
+	return mutating[operation]
+}
+
+// nested lists the composite operations whose requests run through the chain as operations of their own.
+var nested = map[string]bool{
+	Download: true,
+}
+
+// Nested reports whether a composite operation runs each of its requests through the chain as an
+// operation of its own (e.g., the ranged GetObject requests of a Download). Middlewares that count
+// requests, such as rate limits, circuits and metrics, should skip nested operations to count them once.
+//
+// @param operation The name of the operation (e.g., "Download").
+// @return Whether the requests of the operation run through the chain themselves.
+func Nested(operation string) bool {
+	return nested[operation]
+}
+
+// Operation describes a single S3 call flowing through the middleware chain.
+//
+// Middlewares may inspect or replace `Input` before calling the next handler,
+// and inspect the remaining fields once it returns.
+type Operation struct {
+	// Name is the name of the S3 operation (e.g., "PutObject").
+	Name string
+
+	// Bucket is the bucket targeted by the operation, if any.
+	Bucket string
+
+	// Key is the object key targeted by the operation, if any.
+	Key string
+
+	// Input is the SDK input of the operation (e.g., *s3.PutObjectInput).
+	Input any
+
+	// Options are SDK request options applied to the call (e.g., a per-call retry policy).
+	Options []request.Option
+
+	// Output is the SDK output of the operation, set once the call returns.
+	Output any
+
+	// Duration is the time spent sending the operation, set once the call returns.
+	Duration time.Duration
+
+	// StatusCode is the HTTP status code of the last response, if any.
+	StatusCode int
+
+	// RequestID is the AWS request ID of the last response, if any.
+	RequestID string
+
+	// Retries is the number of times the SDK retried the operation.
+	Retries int
+
+	// BytesSent is the size of the request bodies sent to S3, if known.
+	BytesSent int64
+
+	// BytesReceived is the size of the response bodies received from S3, if known.
+	BytesReceived int64
+
+	// Err is the error returned by the operation, set once the call returns.
+	Err error
+}
+
+// ErrNoOutput is returned when a middleware answers an operation without calling the next
+// handler, returning no error, but without setting `Operation.Output` either.
+var ErrNoOutput = errors.New("sthree: operation answered without output")
+
+// Handler executes an operation.
+type Handler func(ctx context.Context, op *Operation) error
+
+// Middleware wraps a Handler with additional behavior, such as logging,
+// metrics, authorization checks or request mutation.
+//
+// A middleware can short-circuit the operation by returning without calling next. When it returns
+// no error, it must set `Operation.Output` to the output of the operation; `Invoke` reports
+// `ErrNoOutput` otherwise.
+type Middleware func(next Handler) Handler
+
+// Chain is an ordered list of middlewares shared by every module derived from a client.
+//
+// The first middleware added is the outermost one. Middlewares added with `UseInner`
+// always run after those added with `Use`, closest to the SDK call.
+// A nil Chain runs operations directly. A Chain is safe for concurrent use.
+type Chain struct {
+	mu          sync.RWMutex
+	middlewares []Middleware
+	inner       []Middleware
+}
+
+// Use appends middlewares to the chain.
+//
+// @param middlewares The middlewares to append, from outermost to innermost.
+func (c *Chain) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// UseInner appends middlewares to the inner part of the chain, which runs after every
+// middleware added with `Use`. It is meant for middlewares that guard the call itself,
+// such as rate limiters, so that outer middlewares can reject operations first.
+//
+// @param middlewares The middlewares to append, from outermost to innermost.
+func (c *Chain) UseInner(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inner = append(c.inner, middlewares...)
+}
+
+// Then wraps the handler with every middleware of the chain.
+//
+// @param h The innermost handler, which actually performs the operation.
+// @return The handler wrapped by the chain.
+func (c *Chain) Then(h Handler) Handler {
+	if c == nil {
+		return h
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for i := len(c.inner) - 1; i >= 0; i-- {
+		h = c.inner[i](h)
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+
+	return h
+}
+
+// Do runs the operation through the chain, with `call` as the innermost handler.
+//
+// `Duration` and `Err` are filled in before the middlewares see the result.
+//
+// @param ctx The context of the operation.
+// @param op The operation to run.
+// @param call The handler that actually performs the operation.
+// @return The error returned by the chain.
+func (c *Chain) Do(ctx context.Context, op *Operation, call Handler) error {
+	return c.Then(func(ctx context.Context, op *Operation) error {
+		start := time.Now()
+		err := call(ctx, op)
+
+		op.Duration = time.Since(start)
+		op.Err = err
+
+		return err
+	})(ctx, op)
+}
+
+// Invoke runs an SDK call through the chain.
+//
+// The call receives `op.Input`, so replacements made by middlewares are honored,
+// its output is stored in `op.Output` and its error is wrapped into an `*errs.Error`. The HTTP status code, the AWS request ID
+// and the number of retries of the underlying request are recorded in the operation.
+// A middleware answering the operation without calling the next handler must set `op.Output`,
+// which is then returned as the output of the call; if it returns no error and leaves no
+// output of type Out, the call fails with `ErrNoOutput`, so callers never get a nil output
+// without an error.
+//
+// @param ctx The context of the operation.
+// @param c The chain to run the operation through; may be nil.
+// @param op The operation, whose `Input` must be of type In.
+// @param call The SDK method performing the operation (e.g., `sdk.GetObjectWithContext`).
+// @return The output of the call, or the error returned by the chain.
+func Invoke[In, Out any](ctx context.Context, c *Chain, op *Operation, call func(context.Context, In, ...request.Option) (Out, error)) (Out, error) {
+	var output Out
+
+	err := c.Do(ctx, op, func(ctx context.Context, op *Operation) error {
+		opts := append([]request.Option{Record(op)}, op.Options...)
+		out, err := call(ctx, op.Input.(In), opts...)
+		output = out
+		op.Output = out
+
+		return errs.Wrap(op.Name, op.Bucket, op.Key, err)
+	})
+
+	if err != nil {
+		if out, ok := op.Output.(Out); ok {
+			output = out
+		}
+		return output, err
+	}
+
+	return Result[Out](op, nil)
+}
+
+// Result returns the output of a completed operation as an Out, along with its error. An operation
+// completed without error but without an output of type Out, because a middleware answered it
+// without calling the next handler, fails with `ErrNoOutput`. `Invoke` returns its outputs through
+// it; composite operations run with `Chain.Do` should too.
+//
+// @param op The completed operation.
+// @param err The error returned by the chain.
+// @return The output of the operation, or the error of the operation or `ErrNoOutput`.
+func Result[Out any](op *Operation, err error) (Out, error) {
+	out, ok := op.Output.(Out)
+	if err != nil {
+		return out, err
+	}
+	if !ok || missing(out) {
+		return out, errs.Wrap(op.Name, op.Bucket, op.Key, ErrNoOutput)
+	}
+
+	return out, nil
+}
+
+// missing reports whether an output is nil, including nil pointers stored in an interface.
+func missing(output any) bool {
+	if output == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(output); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// Attach runs the chain around the sending of an already built SDK request.
+//
+// It is used for requests handed back to the caller unsent, so the chain runs
+// when the caller sends them, once per attempt: middlewares see each attempt as
+// an operation of its own (e.g., the rate limiter waits before every attempt, and
+// the auditor records every attempt). Each attempt only sees the errors of sending
+// the request, since the response is validated once the chain has returned.
+//
+// The options of the operation are applied to the request right away, and the
+// options added by the middlewares are applied once, when the first attempt is sent.
+//
+// @param req The request to attach the chain to.
+// @param c The chain to run; may be nil.
+// @param op The operation describing the request.
+func Attach(req *request.Request, c *Chain, op *Operation) {
+	req.ApplyOptions(op.Options...)
+	if c == nil {
+		return
+	}
+
+	handlers := req.Handlers.Copy()
+	send := handlers.Send
+
+	op.Input = req.Params
+	op.Output = req.Data
+
+	// The options applied above, which the middlewares append theirs to on every attempt.
+	base := op.Options[:len(op.Options):len(op.Options)]
+	applied := false
+
+	req.Handlers.Send.Clear()
+	req.Handlers.Send.PushBack(func(r *request.Request) {
+		op.Options = base
+
+		sent := false
+		r.Error = c.Do(r.Context(), op, func(ctx context.Context, op *Operation) error {
+			if !applied {
+				applied = true
+				r.ApplyOptions(op.Options[len(base):]...)
+			}
+
+			sent = true
+			send.Run(r)
+			record(op, r)
+
+			return r.Error
+		})
+
+		// A middleware answered the operation without sending it, so there is no response to unmarshal.
+		if !sent && r.Error == nil {
+			r.Handlers.UnmarshalMeta.Clear()
+			r.Handlers.ValidateResponse.Clear()
+			r.Handlers.Unmarshal.Clear()
+		}
+	})
+}
+
+// Record returns a request option that copies the status code, the request ID, the retry
+// count and the body sizes of the request into the operation once it completes.
+//
+// @param op The operation to record into.
+// @return A request option for SDK calls.
+func Record(op *Operation) request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			record(op, r)
+		})
+	}
+}
+
+// Composite returns a request option for the SDK requests issued by a composite operation,
+// such as a multipart upload, and a function that records their totals into the operation
+// once they have all completed. Bytes and retries are summed, while the status code and
+// the request ID are those of the last request to complete.
+//
+// @param op The composite operation.
+// @return A request option for every SDK request of the operation, and the function recording their totals.
+func Composite(op *Operation) (request.Option, func()) {
+	var mu sync.Mutex
+	total := Operation{}
+
+	option := func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			last := Operation{}
+			record(&last, r)
+
+			total.StatusCode = last.StatusCode
+			total.RequestID = last.RequestID
+			total.Retries += last.Retries
+			total.BytesSent += last.BytesSent
+			total.BytesReceived += last.BytesReceived
+		})
+	}
+
+	done := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		op.StatusCode = total.StatusCode
+		op.RequestID = total.RequestID
+		op.Retries = total.Retries
+		op.BytesSent = total.BytesSent
+		op.BytesReceived = total.BytesReceived
+	}
+
+	return option, done
+}
+
+func record(op *Operation, r *request.Request) {
+	if r.HTTPRequest != nil && r.HTTPRequest.ContentLength > 0 {
+		op.BytesSent = r.HTTPRequest.ContentLength
+	}
+	if r.HTTPResponse != nil {
+		op.StatusCode = r.HTTPResponse.StatusCode
+		if r.HTTPResponse.ContentLength > 0 {
+			op.BytesReceived = r.HTTPResponse.ContentLength
+		}
+	}
+	op.RequestID = r.RequestID
+	op.Retries = r.RetryCount
+}
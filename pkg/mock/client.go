@@ -0,0 +1,78 @@
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/avila-r/sthree"
+)
+
+// Start starts an in-memory S3 server (see `NewServer`), closed when the test ends.
+//
+// @param t The test the server is started for.
+// @return The started server.
+func Start(t *testing.T) *Server {
+	t.Helper()
+
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Client opens a client on an in-memory S3 server started for the test, in us-east-1,
+// with path-style addressing and static credentials.
+//
+// @param t The test the client is opened for; it fails if the client cannot be opened.
+// @param opts Additional options of the client (e.g., `sthree.WithMetrics`), applied after the defaults.
+// @return The opened client and its server.
+func Client(t *testing.T, opts ...sthree.Option) (*sthree.Sthree, *Server) {
+	t.Helper()
+
+	server := Start(t)
+	return open(t, server.URL, opts...), server
+}
+
+// Connect is the same as Client, with a server answering every request with a handler instead,
+// such as one failing them to exercise retries.
+//
+// @param t The test the client is opened for; it fails if the client cannot be opened.
+// @param handler The handler of the requests sent to the server.
+// @param opts Additional options of the client (e.g., `sthree.WithRetry`), applied after the defaults.
+// @return The opened client and its server.
+func Connect(t *testing.T, handler http.Handler, opts ...sthree.Option) (*sthree.Sthree, *httptest.Server) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return open(t, server.URL, opts...), server
+}
+
+// Options returns the options of the clients of Client and Connect, for tests opening clients
+// themselves, such as several clients on a single server.
+//
+// @param endpoint The URL of the server.
+// @return The options connecting a client to the server, in us-east-1, with static credentials.
+func Options(endpoint string) []sthree.Option {
+	return []sthree.Option{
+		sthree.WithRegion("us-east-1"),
+		sthree.WithEndpoint(endpoint),
+		sthree.WithPathStyle(),
+		sthree.WithStaticCredentials("id", "secret", ""),
+	}
+}
+
+func open(t *testing.T, endpoint string, opts ...sthree.Option) *sthree.Sthree {
+	t.Helper()
+
+	client, err := sthree.Open(session.Must(session.NewSession()), append(Options(endpoint), opts...)...)
+	if err != nil {
+		t.Fatalf("failed to open client - %v", err.Error())
+	}
+
+	return client
+}
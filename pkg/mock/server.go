@@ -0,0 +1,353 @@
+package mock
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is an in-memory, path-style S3 server meant for tests.
+//
+// It implements enough of the S3 API (buckets, objects, listings, CORS, ranged
+// reads and multipart uploads) to exercise the sthree modules without a live endpoint.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	buckets  map[string]map[string]object
+	uploads  map[string]map[int][]byte
+	headers  map[string]http.Header
+	requests []string
+}
+
+// object is a stored object.
+type object struct {
+	body     []byte
+	etag     string
+	headers  http.Header
+	modified time.Time
+}
+
+// NewServer starts an in-memory S3 server. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		buckets: map[string]map[string]object{},
+		uploads: map[string]map[int][]byte{},
+		headers: map[string]http.Header{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+// Requests returns every request received so far, formatted as "METHOD /path".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.requests...)
+}
+
+// Object returns the body of a stored object.
+func (s *Server) Object(bucket, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, ok := s.buckets[bucket][key]
+	return obj.body, ok
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path+multipart(r.URL.Query()))
+	w.Header().Set("X-Amz-Request-Id", fmt.Sprintf("mock-%d", len(s.requests)))
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	switch {
+	case bucket == "":
+		s.listBuckets(w)
+	case key == "" && query.Has("cors"):
+		if _, ok := s.buckets[bucket]; !ok {
+			fail(w, http.StatusNotFound, "NoSuchBucket")
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+	case key == "" && r.Method == http.MethodPut:
+		if _, ok := s.buckets[bucket]; ok {
+			fail(w, http.StatusConflict, "BucketAlreadyOwnedByYou")
+			return
+		}
+		s.buckets[bucket] = map[string]object{}
+	case key == "" && r.Method == http.MethodDelete:
+		if _, ok := s.buckets[bucket]; !ok {
+			fail(w, http.StatusNotFound, "NoSuchBucket")
+			return
+		}
+		delete(s.buckets, bucket)
+		w.WriteHeader(http.StatusNoContent)
+	case key == "" && r.Method == http.MethodHead:
+		if _, ok := s.buckets[bucket]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Amz-Bucket-Region", "us-east-1")
+	case key == "":
+		s.listObjects(w, bucket, query.Get("prefix"))
+	case query.Has("uploads") || query.Has("uploadId"):
+		s.serveMultipart(w, r, bucket, key)
+	default:
+		s.serveObject(w, r, bucket, key)
+	}
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		fail(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		obj := newObject(body, r.Header)
+		objects[key] = obj
+		w.Header().Set("ETag", obj.etag)
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet, http.MethodHead:
+		obj, ok := objects[key]
+		if !ok {
+			fail(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		for name, values := range obj.headers {
+			w.Header()[name] = values
+		}
+		w.Header().Set("ETag", obj.etag)
+		w.Header().Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+
+		body, status := obj.body, http.StatusOK
+		if start, end, ok := parseRange(r.Header.Get("Range"), len(body)); ok {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(body)))
+			body, status = body[start:end+1], http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	default:
+		fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// serveMultipart handles the creation, parts, completion and abortion of multipart uploads.
+// Completed uploads are stored as a single object with the headers of their creation request.
+func (s *Server) serveMultipart(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		fail(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	query := r.URL.Query()
+	if r.Method == http.MethodPost && query.Has("uploads") {
+		id := fmt.Sprintf("upload-%d", len(s.requests))
+		s.uploads[id] = map[int][]byte{}
+		s.headers[id] = r.Header.Clone()
+
+		xml.NewEncoder(w).Encode(struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: id})
+		return
+	}
+
+	id := query.Get("uploadId")
+	parts, ok := s.uploads[id]
+	if !ok {
+		fail(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		number, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil {
+			fail(w, http.StatusBadRequest, "InvalidArgument")
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		parts[number] = body
+		sum := md5.Sum(body)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	case http.MethodPost:
+		io.Copy(io.Discard, r.Body)
+		numbers := make([]int, 0, len(parts))
+		for number := range parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+
+		body := []byte{}
+		for _, number := range numbers {
+			body = append(body, parts[number]...)
+		}
+		obj := newObject(body, s.headers[id])
+		objects[key] = obj
+		delete(s.uploads, id)
+		delete(s.headers, id)
+
+		xml.NewEncoder(w).Encode(struct {
+			XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			ETag     string
+			Location string
+		}{Bucket: bucket, Key: key, ETag: obj.etag, Location: "/" + bucket + "/" + key})
+	case http.MethodDelete:
+		delete(s.uploads, id)
+		delete(s.headers, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// Uploads returns the number of multipart uploads created but neither completed nor aborted.
+func (s *Server) Uploads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.uploads)
+}
+
+func newObject(body []byte, header http.Header) object {
+	sum := md5.Sum(body)
+	obj := object{
+		body:     body,
+		etag:     `"` + hex.EncodeToString(sum[:]) + `"`,
+		headers:  http.Header{},
+		modified: time.Now().UTC(),
+	}
+	for name, values := range header {
+		if strings.HasPrefix(name, "X-Amz-Meta-") || name == "Content-Type" {
+			obj.headers[name] = values
+		}
+	}
+
+	return obj
+}
+
+// multipart formats the query of multipart requests for `Requests`.
+func multipart(query url.Values) string {
+	switch {
+	case query.Has("uploads"):
+		return "?uploads"
+	case query.Has("partNumber"):
+		return "?partNumber=" + query.Get("partNumber")
+	case query.Has("uploadId"):
+		return "?uploadId"
+	}
+
+	return ""
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) {
+	type bucket struct {
+		Name         string
+		CreationDate time.Time
+	}
+	result := struct {
+		XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+		Buckets []bucket `xml:"Buckets>Bucket"`
+	}{}
+
+	for name := range s.buckets {
+		result.Buckets = append(result.Buckets, bucket{Name: name, CreationDate: time.Now().UTC()})
+	}
+	sort.Slice(result.Buckets, func(i, j int) bool {
+		return result.Buckets[i].Name < result.Buckets[j].Name
+	})
+
+	xml.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, bucket, prefix string) {
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		fail(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	type content struct {
+		Key          string
+		ETag         string
+		Size         int
+		LastModified time.Time
+	}
+	result := struct {
+		XMLName  xml.Name `xml:"ListBucketResult"`
+		Name     string
+		Prefix   string
+		KeyCount int
+		Contents []content
+	}{Name: bucket, Prefix: prefix}
+
+	for key, obj := range objects {
+		if strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{Key: key, ETag: obj.etag, Size: len(obj.body), LastModified: obj.modified})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool {
+		return result.Contents[i].Key < result.Contents[j].Key
+	})
+	result.KeyCount = len(result.Contents)
+
+	xml.NewEncoder(w).Encode(result)
+}
+
+// parseRange parses a single "bytes=start-end" range header.
+func parseRange(header string, size int) (int, int, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return 0, 0, false
+	}
+
+	from, to, _ := strings.Cut(spec, "-")
+	start, err := strconv.Atoi(from)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+
+	end := size - 1
+	if n, err := strconv.Atoi(to); err == nil && n < end {
+		end = n
+	}
+
+	return start, end, true
+}
+
+func fail(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a token bucket whose rate can be lowered and restored at runtime.
+type tokenBucket struct {
+	mu     sync.Mutex
+	base   float64
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit Limit) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(limit.Rate))
+	}
+
+	return &tokenBucket{
+		base:   limit.Rate,
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before it is available.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a reserved token, for an operation that will not be sent.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+}
+
+// idle reports whether the bucket is full at its configured rate, so dropping it and creating
+// a new one later makes no difference.
+func (b *tokenBucket) idle(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	return b.tokens >= b.burst && b.rate >= b.base
+}
+
+// decrease halves the rate, down to a tenth of the configured one.
+func (b *tokenBucket) decrease() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.rate = math.Max(b.rate/2, b.base/10)
+}
+
+// increase restores the rate by a twentieth of the configured one, up to the configured one.
+func (b *tokenBucket) increase() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate < b.base {
+		b.refill(time.Now())
+		b.rate = math.Min(b.rate+b.base/20, b.base)
+	}
+}
+
+// refill adds the tokens accumulated since the last refill. It must be called with the lock held.
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
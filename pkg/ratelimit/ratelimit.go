@@ -0,0 +1,303 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// Class groups operations that share the same S3 request-rate limits.
+type Class string
+
+const (
+	// Read covers GET, HEAD, list and download operations.
+	Read Class = "read"
+
+	// Write covers PUT, COPY, POST and DELETE operations.
+	Write Class = "write"
+)
+
+// Default per-prefix rates used by adaptive limiting when no prefix limit is configured.
+// They match the documented S3 request rates per partitioned prefix.
+const (
+	DefaultReadRate  = 5500
+	DefaultWriteRate = 3500
+)
+
+// Limit is a token-bucket limit. The zero value means unlimited.
+type Limit struct {
+	// Rate is the number of operations allowed per second.
+	Rate float64 `yaml:"rate"`
+
+	// Burst is the number of operations allowed at once. Defaults to the rate, rounded up.
+	Burst int `yaml:"burst"`
+}
+
+// Limits are the limits of a single operation class at every scope.
+type Limits struct {
+	// Global limits all the operations of the client.
+	Global Limit `yaml:"global"`
+
+	// Bucket limits the operations of each bucket independently.
+	Bucket Limit `yaml:"bucket"`
+
+	// Prefix limits the operations of each key prefix independently.
+	Prefix Limit `yaml:"prefix"`
+}
+
+// Config configures the client-side rate limiter.
+type Config struct {
+	// Read are the limits of read operations.
+	Read Limits `yaml:"read"`
+
+	// Write are the limits of write operations.
+	Write Limits `yaml:"write"`
+
+	// Delimiter separates the prefix of a key from its name; defaults to "/".
+	// A key's prefix is everything up to and including its last delimiter.
+	Delimiter string `yaml:"delimiter"`
+
+	// Adaptive halves the rates that apply to an operation whenever S3 answers one of its
+	// attempts, retries included, with 503 SlowDown, and slowly restores them as operations
+	// succeed again.
+	Adaptive bool `yaml:"adaptive"`
+}
+
+// sweepThreshold is the number of token buckets past which idle ones are evicted.
+const sweepThreshold = 1024
+
+// Limiter throttles S3 operations with token buckets scoped globally, per bucket and per key prefix,
+// for each operation class. A Limiter is safe for concurrent use.
+//
+// Token buckets are created for every bucket and prefix on first use, and evicted once they are
+// idle (full again at their configured rate), so workloads over many prefixes use bounded memory.
+type Limiter struct {
+	cfg     Config
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	sweepAt int
+}
+
+// New creates a limiter from the given configuration.
+//
+// @param cfg The limits to enforce.
+// @return A pointer to a new `Limiter`.
+func New(cfg Config) *Limiter {
+	if cfg.Delimiter == "" {
+		cfg.Delimiter = "/"
+	}
+
+	return &Limiter{
+		cfg:     cfg,
+		buckets: map[string]*tokenBucket{},
+		sweepAt: sweepThreshold,
+	}
+}
+
+// ClassOf returns the class of the named operation.
+//
+// @param operation The name of the operation (e.g., "GetObject").
+// @return Read for GET, HEAD, list and download operations, Write otherwise.
+func ClassOf(operation string) Class {
+	if operation == middleware.Download {
+		return Read
+	}
+
+	for _, prefix := range []string{"Get", "Head", "List"} {
+		if strings.HasPrefix(operation, prefix) {
+			return Read
+		}
+	}
+
+	return Write
+}
+
+// Wait blocks until the operation is allowed by every limit that applies to it.
+// A token is reserved in every scope at once, and all of them are given back if the wait is cancelled.
+//
+// @param ctx The context of the operation; cancelling it stops the wait.
+// @param op The operation to wait for.
+// @return The context error if it is cancelled while waiting.
+func (l *Limiter) Wait(ctx context.Context, op *middleware.Operation) error {
+	scopes := l.scopes(op)
+
+	delay := time.Duration(0)
+	for _, b := range scopes {
+		delay = max(delay, b.reserve())
+	}
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reserved tokens back, since the operation will not be sent.
+		for _, b := range scopes {
+			b.cancel()
+		}
+
+		return ctx.Err()
+	}
+}
+
+// Observe adapts the rates that apply to an operation once it completes,
+// if adaptive limiting is enabled.
+//
+// @param op The completed operation.
+// @param err The error it returned.
+func (l *Limiter) Observe(op *middleware.Operation, err error) {
+	if !l.cfg.Adaptive {
+		return
+	}
+
+	// Errors of unsent requests reach the chain unwrapped, so classify them here.
+	throttled := errors.Is(errs.Wrap(op.Name, op.Bucket, op.Key, err), errs.ErrThrottled)
+	for _, b := range l.scopes(op) {
+		if throttled {
+			b.decrease()
+		} else if err == nil {
+			b.increase()
+		}
+	}
+}
+
+// observeAttempts returns a request option lowering the rates of the operation on every
+// throttled attempt of its SDK requests, including the ones retried by the SDK, and sets
+// observed once it did, so the final error is not counted twice.
+func (l *Limiter) observeAttempts(op *middleware.Operation, observed *atomic.Bool) request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Retry.PushFront(func(r *request.Request) {
+			if errors.Is(errs.Wrap(op.Name, op.Bucket, op.Key, r.Error), errs.ErrThrottled) {
+				observed.Store(true)
+				for _, b := range l.scopes(op) {
+					b.decrease()
+				}
+			}
+		})
+	}
+}
+
+// Middleware returns a middleware that runs every operation through the limiter. Nested operations
+// (see `middleware.Nested`) are not limited themselves, since each of their requests is. The requests
+// returned unsent by `Sthree.Requests` run the chain once per attempt (see `middleware.Attach`), so
+// they wait for the limiter before every attempt.
+//
+// @return A middleware that waits for the limiter before calling the next handler.
+func (l *Limiter) Middleware() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, op *middleware.Operation) error {
+			if middleware.Nested(op.Name) {
+				return next(ctx, op)
+			}
+
+			if err := l.Wait(ctx, op); err != nil {
+				return err
+			}
+
+			var observed atomic.Bool
+			if l.cfg.Adaptive {
+				op.Options = append(op.Options, l.observeAttempts(op, &observed))
+			}
+
+			err := next(ctx, op)
+			if !observed.Load() {
+				l.Observe(op, err)
+			}
+
+			return err
+		}
+	}
+}
+
+// scopes returns the token buckets that apply to the operation, creating them on first use.
+func (l *Limiter) scopes(op *middleware.Operation) []*tokenBucket {
+	class := ClassOf(op.Name)
+
+	limits, fallback := l.cfg.Read, float64(DefaultReadRate)
+	if class == Write {
+		limits, fallback = l.cfg.Write, DefaultWriteRate
+	}
+	if l.cfg.Adaptive && limits.Prefix.Rate == 0 {
+		limits.Prefix = Limit{Rate: fallback}
+	}
+
+	prefix := ""
+	if i := strings.LastIndex(op.Key, l.cfg.Delimiter); i >= 0 {
+		prefix = op.Key[:i+len(l.cfg.Delimiter)]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	scopes := []*tokenBucket{}
+	for _, scope := range []struct {
+		name  string
+		limit Limit
+		skip  bool
+	}{
+		{name: string(class), limit: limits.Global},
+		{name: string(class) + ":" + op.Bucket, limit: limits.Bucket, skip: op.Bucket == ""},
+		{name: string(class) + ":" + op.Bucket + "/" + prefix, limit: limits.Prefix, skip: op.Bucket == ""},
+	} {
+		if scope.skip || scope.limit.Rate <= 0 {
+			continue
+		}
+
+		b, ok := l.buckets[scope.name]
+		if !ok {
+			b = newTokenBucket(scope.limit)
+			l.buckets[scope.name] = b
+		}
+		scopes = append(scopes, b)
+	}
+
+	if len(l.buckets) >= l.sweepAt {
+		l.sweep(scopes)
+	}
+
+	return scopes
+}
+
+// sweep evicts the idle token buckets other than the given ones, which are about to be used.
+// The next sweep runs once the number of buckets has doubled, so sweeps cost amortized constant time.
+// It must be called with the lock held.
+func (l *Limiter) sweep(keep []*tokenBucket) {
+	now := time.Now()
+
+next:
+	for name, b := range l.buckets {
+		for _, k := range keep {
+			if b == k {
+				continue next
+			}
+		}
+		if b.idle(now) {
+			delete(l.buckets, name)
+		}
+	}
+
+	l.sweepAt = max(sweepThreshold, 2*len(l.buckets))
+}
+
+// Len returns the number of token buckets held by the limiter.
+//
+// @return The number of scopes with a token bucket.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
@@ -0,0 +1,179 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/ratelimit"
+	"github.com/avila-r/sthree/pkg/retry"
+)
+
+func send(limiter *ratelimit.Limiter, ctx context.Context, op middleware.Operation, err error) error {
+	handler := limiter.Middleware()(func(ctx context.Context, op *middleware.Operation) error {
+		return err
+	})
+
+	return handler(ctx, &op)
+}
+
+func Test_ClassOf(t *testing.T) {
+	reads := []string{middleware.GetObject, middleware.ListObjects, middleware.ListBuckets, middleware.Download, "HeadObject"}
+	for _, name := range reads {
+		if ratelimit.ClassOf(name) != ratelimit.Read {
+			t.Errorf("expected %v to be a read", name)
+		}
+	}
+
+	writes := []string{middleware.PutObject, middleware.DeleteObject, middleware.Upload, middleware.CreateBucket}
+	for _, name := range writes {
+		if ratelimit.ClassOf(name) != ratelimit.Write {
+			t.Errorf("expected %v to be a write", name)
+		}
+	}
+}
+
+func Test_Limiter(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Write: ratelimit.Limits{Prefix: ratelimit.Limit{Rate: 10, Burst: 1}},
+	})
+
+	put := middleware.Operation{Name: middleware.PutObject, Bucket: "assets", Key: "images/logo.png"}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := send(limiter, context.Background(), put, nil); err != nil {
+			t.Fatalf("failed to send operation - %v", err.Error())
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected writes to the same prefix to be throttled, took %v", elapsed)
+	}
+
+	t.Run("other prefixes and classes are independent", func(t *testing.T) {
+		start := time.Now()
+		other := middleware.Operation{Name: middleware.PutObject, Bucket: "assets", Key: "docs/readme.md"}
+		get := middleware.Operation{Name: middleware.GetObject, Bucket: "assets", Key: "images/logo.png"}
+		send(limiter, context.Background(), other, nil)
+		send(limiter, context.Background(), get, nil)
+
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("expected operations not to be throttled, took %v", elapsed)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		send(limiter, context.Background(), put, nil)
+		if err := send(limiter, ctx, put, nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected wait to be cancelled - %v", err)
+		}
+	})
+}
+
+func Test_Adaptive(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Read:     ratelimit.Limits{Bucket: ratelimit.Limit{Rate: 100, Burst: 1}},
+		Adaptive: true,
+	})
+
+	get := middleware.Operation{Name: middleware.GetObject, Bucket: "assets", Key: "logo.png"}
+	throttled := &errs.Error{Code: "SlowDown", Kinds: []error{errs.ErrThrottled}}
+
+	// Halving 100/s three times leaves 12.5/s, i.e. 80ms between operations.
+	for i := 0; i < 3; i++ {
+		send(limiter, context.Background(), get, throttled)
+	}
+
+	start := time.Now()
+	send(limiter, context.Background(), get, nil)
+	send(limiter, context.Background(), get, nil)
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("expected rate to be lowered after SlowDown responses, took %v", elapsed)
+	}
+}
+
+func Test_Eviction(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Write: ratelimit.Limits{Prefix: ratelimit.Limit{Rate: 1e6, Burst: 1}},
+	})
+
+	for i := 0; i < 10000; i++ {
+		put := middleware.Operation{Name: middleware.PutObject, Bucket: "assets", Key: fmt.Sprintf("%d/logo.png", i)}
+		if err := send(limiter, context.Background(), put, nil); err != nil {
+			t.Fatalf("failed to send operation - %v", err.Error())
+		}
+	}
+
+	if n := limiter.Len(); n > 2048 {
+		t.Errorf("expected idle token buckets to be evicted, holding %v", n)
+	}
+}
+
+func Test_Wait_Cancelled(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Write: ratelimit.Limits{
+			Global: ratelimit.Limit{Rate: 10, Burst: 2},
+			Prefix: ratelimit.Limit{Rate: 0.001, Burst: 1},
+		},
+	})
+
+	images := middleware.Operation{Name: middleware.PutObject, Bucket: "assets", Key: "images/logo.png"}
+	send(limiter, context.Background(), images, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := send(limiter, ctx, images, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected wait to be cancelled - %v", err)
+	}
+
+	// The global token reserved by the cancelled operation was given back.
+	start := time.Now()
+	send(limiter, context.Background(), middleware.Operation{Name: middleware.PutObject, Bucket: "assets", Key: "docs/readme.md"}, nil)
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected the global token to be given back, took %v", elapsed)
+	}
+}
+
+func Test_Adaptive_Retries(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("<Error><Code>SlowDown</Code><Message>slow down</Message></Error>"))
+			return
+		}
+		w.Write([]byte("logo"))
+	})
+
+	client, _ := mock.Connect(t, handler,
+		sthree.WithRetry(retry.Policy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		sthree.WithRateLimit(ratelimit.Config{
+			Read:     ratelimit.Limits{Bucket: ratelimit.Limit{Rate: 100, Burst: 1}},
+			Adaptive: true,
+		}),
+	)
+
+	// The operation succeeds on its fourth attempt, after three SlowDown responses.
+	if _, err := client.Bucket("assets").Get("logo.png"); err != nil {
+		t.Fatalf("failed to get object - %v", err.Error())
+	}
+
+	// Halving 100/s three times leaves 12.5/s, i.e. 80ms between operations.
+	start := time.Now()
+	client.Bucket("assets").Get("logo.png")
+	client.Bucket("assets").Get("logo.png")
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected retried SlowDown responses to lower the rate, took %v", elapsed)
+	}
+}
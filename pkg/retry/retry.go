@@ -0,0 +1,209 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// Default values used for the zero fields of a `Policy`.
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 100 * time.Millisecond
+	DefaultMaxDelay    = 20 * time.Second
+)
+
+var (
+	// DefaultStatusCodes are the HTTP status codes retried when a policy lists none.
+	DefaultStatusCodes = []int{
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+
+	// DefaultCodes are the AWS error codes retried when a policy lists none.
+	DefaultCodes = []string{
+		"RequestError",
+		"RequestTimeout",
+		"RequestTimeoutException",
+		"InternalError",
+		"SlowDown",
+		"Throttling",
+		"ThrottlingException",
+		"RequestLimitExceeded",
+		"ExpiredToken",
+	}
+
+	// throttleCodes are the error codes meaning the request was refused before being processed,
+	// which makes them safe to retry even for conditional writes.
+	throttleCodes = []string{
+		"SlowDown",
+		"Throttling",
+		"ThrottlingException",
+		"RequestLimitExceeded",
+	}
+)
+
+// Policy describes how failed S3 calls are retried.
+//
+// A Policy replaces the default retryer of the AWS SDK, either for every call
+// of a client (`Config.Retry`) or for a single call (the `Retry` field of
+// `objects.Get`, `objects.Put`, `objects.Delete` and `objects.List`).
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// The zero value means DefaultMaxAttempts; 1 disables retries.
+	MaxAttempts int `yaml:"max_attempts"`
+
+	// BaseDelay is the delay before the first retry, doubled on every further attempt.
+	// The zero value means DefaultBaseDelay.
+	BaseDelay time.Duration `yaml:"base_delay"`
+
+	// MaxDelay caps the delay between two attempts.
+	// The zero value means DefaultMaxDelay.
+	MaxDelay time.Duration `yaml:"max_delay"`
+
+	// FullJitter picks every delay uniformly between zero and the exponential backoff,
+	// spreading the retries of concurrent callers.
+	FullJitter bool `yaml:"full_jitter"`
+
+	// StatusCodes are the HTTP status codes that are retried.
+	// When empty, DefaultStatusCodes is used.
+	StatusCodes []int `yaml:"status_codes"`
+
+	// Codes are the AWS error codes that are retried.
+	// When empty, DefaultCodes is used.
+	Codes []string `yaml:"codes"`
+
+	// RetryConditionalWrites retries conditional writes (requests carrying If-Match or
+	// If-None-Match) on any retryable error. By default they are only retried when
+	// throttled, as other failures may hide a write that already succeeded.
+	RetryConditionalWrites bool `yaml:"retry_conditional_writes"`
+}
+
+// Option returns a request option applying the policy to a single SDK call.
+//
+// @return A request option for SDK calls.
+func (p Policy) Option() request.Option {
+	return func(r *request.Request) {
+		r.Retryer = p.Retryer()
+		r.Config.EnforceShouldRetryCheck = aws.Bool(true)
+	}
+}
+
+// Retryer adapts the policy into an AWS SDK retryer.
+//
+// @return A retryer for `aws.Config.Retryer`.
+func (p Policy) Retryer() request.Retryer {
+	return retryer{p}
+}
+
+// Delay returns the delay to wait before the given retry, starting at zero.
+//
+// @param retry The number of retries already made.
+// @return The exponential backoff, capped at MaxDelay, with full jitter if enabled.
+func (p Policy) Delay(retry int) time.Duration {
+	base, max := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = DefaultBaseDelay
+	}
+	if max <= 0 {
+		max = DefaultMaxDelay
+	}
+
+	delay := max
+	if backoff := float64(base) * math.Pow(2, float64(retry)); backoff < float64(max) {
+		delay = time.Duration(backoff)
+	}
+
+	if p.FullJitter {
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+
+	return delay
+}
+
+// Retryable reports whether an error is retried by the policy, based on its status and error codes.
+//
+// @param err The error returned by an SDK call.
+// @return True if the error has a retryable status or error code.
+func (p Policy) Retryable(err error) bool {
+	status, code := classify(err)
+	if code == request.CanceledErrorCode || code == "" && status == 0 {
+		return false
+	}
+
+	statuses, codes := p.StatusCodes, p.Codes
+	if len(statuses) == 0 {
+		statuses = DefaultStatusCodes
+	}
+	if len(codes) == 0 {
+		codes = DefaultCodes
+	}
+
+	return slices.Contains(statuses, status) || slices.Contains(codes, code)
+}
+
+// attempts returns the total number of attempts allowed by the policy.
+func (p Policy) attempts() int {
+	if p.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+
+	return p.MaxAttempts
+}
+
+// classify extracts the HTTP status and AWS error codes of an error.
+func classify(err error) (int, string) {
+	status, code := 0, ""
+	if aerr, ok := err.(awserr.Error); ok {
+		code = aerr.Code()
+	}
+	if rerr, ok := err.(awserr.RequestFailure); ok {
+		status = rerr.StatusCode()
+	}
+
+	return status, code
+}
+
+// retryer implements `request.Retryer` on top of a policy.
+type retryer struct {
+	policy Policy
+}
+
+func (r retryer) MaxRetries() int {
+	return r.policy.attempts() - 1
+}
+
+func (r retryer) RetryRules(req *request.Request) time.Duration {
+	return r.policy.Delay(req.RetryCount)
+}
+
+func (r retryer) ShouldRetry(req *request.Request) bool {
+	if req.Error == nil || !r.policy.Retryable(req.Error) {
+		return false
+	}
+
+	if conditional(req) && !r.policy.RetryConditionalWrites {
+		_, code := classify(req.Error)
+		return slices.Contains(throttleCodes, code)
+	}
+
+	return true
+}
+
+// conditional reports whether the request is a write guarded by a precondition.
+func conditional(req *request.Request) bool {
+	if req.HTTPRequest == nil || req.HTTPRequest.Method == http.MethodGet || req.HTTPRequest.Method == http.MethodHead {
+		return false
+	}
+
+	header := req.HTTPRequest.Header
+	return header.Get("If-Match") != "" || header.Get("If-None-Match") != ""
+}
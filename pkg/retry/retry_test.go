@@ -0,0 +1,106 @@
+package retry_test
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/retry"
+)
+
+func Test_Delay(t *testing.T) {
+	policy := retry.Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, delay := range expected {
+		if d := policy.Delay(i); d != delay {
+			t.Errorf("unexpected delay for retry %v - %v", i, d)
+		}
+	}
+
+	policy.FullJitter = true
+	for i := 0; i < 100; i++ {
+		if d := policy.Delay(3); d < 0 || d > 800*time.Millisecond {
+			t.Fatalf("jittered delay out of bounds - %v", d)
+		}
+	}
+}
+
+func Test_Policy(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if strings.Contains(r.URL.Path, "internal") {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("<Error><Code>InternalError</Code></Error>"))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<Error><Code>SlowDown</Code></Error>"))
+	})
+
+	client, _ := mock.Connect(t, handler,
+		sthree.WithRetry(retry.Policy{MaxAttempts: 4, BaseDelay: time.Millisecond}),
+	)
+
+	t.Run("client policy", func(t *testing.T) {
+		calls.Store(0)
+		if _, err := client.Bucket("assets").Get("logo.png"); err == nil {
+			t.Fatalf("expected get to fail")
+		}
+
+		if n := calls.Load(); n != 4 {
+			t.Errorf("expected 4 attempts, got %v", n)
+		}
+	})
+
+	t.Run("per-call override", func(t *testing.T) {
+		calls.Store(0)
+		params := objects.Get{Retry: &retry.Policy{MaxAttempts: 2, BaseDelay: time.Millisecond}}
+		if _, err := client.Bucket("assets").Get("logo.png", params); err == nil {
+			t.Fatalf("expected get to fail")
+		}
+
+		if n := calls.Load(); n != 2 {
+			t.Errorf("expected 2 attempts, got %v", n)
+		}
+	})
+
+	t.Run("conditional writes are only retried when throttled", func(t *testing.T) {
+		calls.Store(0)
+		req, _ := client.Requests.PutObject(objects.Put{Bucket: "assets", Key: "logo.png", Body: "logo"})
+		req.HTTPRequest.Header.Set("If-None-Match", "*")
+		if err := req.Send(); err == nil {
+			t.Fatalf("expected put to fail")
+		}
+
+		if n := calls.Load(); n != 4 {
+			t.Errorf("expected throttled conditional write to be retried, got %v attempts", n)
+		}
+
+		calls.Store(0)
+		req, _ = client.Requests.PutObject(objects.Put{Bucket: "assets", Key: "internal.png", Body: "logo"})
+		req.HTTPRequest.Header.Set("If-None-Match", "*")
+		if err := req.Send(); err == nil {
+			t.Fatalf("expected put to fail")
+		}
+
+		if n := calls.Load(); n != 1 {
+			t.Errorf("expected conditional write not to be retried, got %v attempts", n)
+		}
+
+		calls.Store(0)
+		if _, err := client.Bucket("assets").Put("internal.png", "logo"); err == nil {
+			t.Fatalf("expected put to fail")
+		}
+
+		if n := calls.Load(); n != 4 {
+			t.Errorf("expected unconditional write to be retried, got %v attempts", n)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package s3api
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// API is the subset of the S3 API the sthree modules are built on.
+//
+// `*s3.S3` and every `s3iface.S3API` implement it, so tests can inject a fake
+// implementing only these methods instead of talking to a live endpoint.
+type API interface {
+	GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error)
+	PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error)
+	DeleteObjectWithContext(ctx aws.Context, input *s3.DeleteObjectInput, opts ...request.Option) (*s3.DeleteObjectOutput, error)
+	ListObjectsV2WithContext(ctx aws.Context, input *s3.ListObjectsV2Input, opts ...request.Option) (*s3.ListObjectsV2Output, error)
+	CreateBucketWithContext(ctx aws.Context, input *s3.CreateBucketInput, opts ...request.Option) (*s3.CreateBucketOutput, error)
+	DeleteBucketWithContext(ctx aws.Context, input *s3.DeleteBucketInput, opts ...request.Option) (*s3.DeleteBucketOutput, error)
+	ListBucketsWithContext(ctx aws.Context, input *s3.ListBucketsInput, opts ...request.Option) (*s3.ListBucketsOutput, error)
+	HeadBucketWithContext(ctx aws.Context, input *s3.HeadBucketInput, opts ...request.Option) (*s3.HeadBucketOutput, error)
+	PutBucketCorsWithContext(ctx aws.Context, input *s3.PutBucketCorsInput, opts ...request.Option) (*s3.PutBucketCorsOutput, error)
+}
+
+// Resolver is implemented by APIs that route each request to a client chosen with the context
+// of the request, such as the per-region clients of region discovery.
+type Resolver interface {
+	// Resolve returns the client serving the requests made with the given context.
+	Resolve(ctx aws.Context) API
+}
+
+// Requester builds unsent SDK requests, as handed back by the requests module.
+//
+// `*s3.S3` and every `s3iface.S3API` implement it.
+type Requester interface {
+	GetObjectRequest(input *s3.GetObjectInput) (*request.Request, *s3.GetObjectOutput)
+	PutObjectRequest(input *s3.PutObjectInput) (*request.Request, *s3.PutObjectOutput)
+	DeleteObjectRequest(input *s3.DeleteObjectInput) (*request.Request, *s3.DeleteObjectOutput)
+	ListObjectsV2Request(input *s3.ListObjectsV2Input) (*request.Request, *s3.ListObjectsV2Output)
+}
+
+var (
+	_ API       = (*s3.S3)(nil)
+	_ API       = (s3iface.S3API)(nil)
+	_ Requester = (*s3.S3)(nil)
+	_ Requester = (s3iface.S3API)(nil)
+)
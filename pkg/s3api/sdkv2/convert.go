@@ -0,0 +1,106 @@
+package sdkv2
+
+import (
+	"reflect"
+)
+
+// convert copies the fields of src into the fields of dst with the same name, converting
+// between the pointer-heavy shapes of aws-sdk-go and the shapes of aws-sdk-go-v2: strings
+// and enums, int64 and int32 numbers, pointers and values, and slices and maps of them.
+// Fields without a compatible counterpart are left untouched.
+//
+// @param dst A pointer to the structure to fill.
+// @param src A pointer to the structure to read.
+func convert(dst, src any) {
+	assign(reflect.ValueOf(dst), reflect.ValueOf(src))
+}
+
+// assign sets dst from src and reports whether it could.
+func assign(dst, src reflect.Value) bool {
+	if !src.IsValid() {
+		return false
+	}
+
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return true
+	}
+
+	if src.Kind() == reflect.Pointer {
+		if src.IsNil() {
+			return false
+		}
+		src = src.Elem()
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if !dst.IsNil() {
+			return assign(dst.Elem(), src)
+		}
+
+		value := reflect.New(dst.Type().Elem())
+		if !assign(value.Elem(), src) {
+			return false
+		}
+
+		dst.Set(value)
+		return true
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		if src.Kind() != reflect.String {
+			return false
+		}
+		dst.SetString(src.String())
+	case reflect.Bool:
+		if src.Kind() != reflect.Bool {
+			return false
+		}
+		dst.SetBool(src.Bool())
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if !src.CanInt() {
+			return false
+		}
+		dst.SetInt(src.Int())
+	case reflect.Struct:
+		if src.Kind() != reflect.Struct {
+			return false
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			field := dst.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if value := src.FieldByName(field.Name); value.IsValid() {
+				assign(dst.Field(i), value)
+			}
+		}
+	case reflect.Slice:
+		if src.Kind() != reflect.Slice {
+			return false
+		}
+		slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			assign(slice.Index(i), src.Index(i))
+		}
+		dst.Set(slice)
+	case reflect.Map:
+		if src.Kind() != reflect.Map || src.Type().Key().Kind() != reflect.String {
+			return false
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		for iter := src.MapRange(); iter.Next(); {
+			key := reflect.New(dst.Type().Key()).Elem()
+			value := reflect.New(dst.Type().Elem()).Elem()
+			if assign(key, iter.Key()) && assign(value, iter.Value()) {
+				m.SetMapIndex(key, value)
+			}
+		}
+		dst.Set(m)
+	default:
+		return false
+	}
+
+	return true
+}
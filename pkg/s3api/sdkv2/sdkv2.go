@@ -0,0 +1,225 @@
+package sdkv2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	s3v2 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+// API runs the sthree modules on an aws-sdk-go-v2 S3 client.
+//
+// Inputs and outputs keep their aws-sdk-go shapes, so every module stays source compatible,
+// and failed calls return `awserr.RequestFailure` errors, so `errs` classifies them as usual.
+// The request options of a call are honored as follows: the status code, request ID and
+// retries are reported to completion handlers, a retryer only limits the number of attempts,
+// and HTTP headers are forwarded. The retries, logging and HTTP settings of `sthree.Config`
+// do not apply; configure the v2 client instead.
+type API struct {
+	// Client is the aws-sdk-go-v2 S3 client the calls are sent with.
+	Client *s3v2.Client
+}
+
+var _ s3api.API = (*API)(nil)
+
+// New wraps an aws-sdk-go-v2 S3 client.
+//
+// @param client The client to send the calls with.
+// @return A pointer to an `API`, to pass to `sthree.WithAPI`.
+func New(client *s3v2.Client) *API {
+	return &API{Client: client}
+}
+
+// NewFromConfig creates an aws-sdk-go-v2 S3 client from the configuration and wraps it.
+//
+// @param cfg The aws-sdk-go-v2 configuration (e.g., from `config.LoadDefaultConfig`).
+// @param optFns Optional settings of the S3 client (e.g., `UsePathStyle`).
+// @return A pointer to an `API`, to pass to `sthree.WithAPI`.
+func NewFromConfig(cfg awsv2.Config, optFns ...func(*s3v2.Options)) *API {
+	return New(s3v2.NewFromConfig(cfg, optFns...))
+}
+
+func (a *API) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	var expires *string
+	output, err := call(ctx, "GetObject", input, opts, func(ctx context.Context, in *s3v2.GetObjectInput, optFns ...func(*s3v2.Options)) (*s3v2.GetObjectOutput, error) {
+		out, err := a.Client.GetObject(ctx, in, optFns...)
+		if out != nil {
+			expires = out.ExpiresString
+		}
+		return out, err
+	}, &s3.GetObjectOutput{})
+
+	output.Expires = expires
+	return output, err
+}
+
+func (a *API) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	return call(ctx, "PutObject", input, opts, a.Client.PutObject, &s3.PutObjectOutput{})
+}
+
+func (a *API) DeleteObjectWithContext(ctx aws.Context, input *s3.DeleteObjectInput, opts ...request.Option) (*s3.DeleteObjectOutput, error) {
+	return call(ctx, "DeleteObject", input, opts, a.Client.DeleteObject, &s3.DeleteObjectOutput{})
+}
+
+func (a *API) ListObjectsV2WithContext(ctx aws.Context, input *s3.ListObjectsV2Input, opts ...request.Option) (*s3.ListObjectsV2Output, error) {
+	return call(ctx, "ListObjectsV2", input, opts, a.Client.ListObjectsV2, &s3.ListObjectsV2Output{})
+}
+
+func (a *API) CreateBucketWithContext(ctx aws.Context, input *s3.CreateBucketInput, opts ...request.Option) (*s3.CreateBucketOutput, error) {
+	return call(ctx, "CreateBucket", input, opts, a.Client.CreateBucket, &s3.CreateBucketOutput{})
+}
+
+func (a *API) DeleteBucketWithContext(ctx aws.Context, input *s3.DeleteBucketInput, opts ...request.Option) (*s3.DeleteBucketOutput, error) {
+	return call(ctx, "DeleteBucket", input, opts, a.Client.DeleteBucket, &s3.DeleteBucketOutput{})
+}
+
+func (a *API) ListBucketsWithContext(ctx aws.Context, input *s3.ListBucketsInput, opts ...request.Option) (*s3.ListBucketsOutput, error) {
+	return call(ctx, "ListBuckets", input, opts, a.Client.ListBuckets, &s3.ListBucketsOutput{})
+}
+
+func (a *API) HeadBucketWithContext(ctx aws.Context, input *s3.HeadBucketInput, opts ...request.Option) (*s3.HeadBucketOutput, error) {
+	return call(ctx, "HeadBucket", input, opts, a.Client.HeadBucket, &s3.HeadBucketOutput{})
+}
+
+func (a *API) PutBucketCorsWithContext(ctx aws.Context, input *s3.PutBucketCorsInput, opts ...request.Option) (*s3.PutBucketCorsOutput, error) {
+	return call(ctx, "PutBucketCors", input, opts, a.Client.PutBucketCors, &s3.PutBucketCorsOutput{})
+}
+
+// call converts the input, sends it through the v2 client and converts the output back.
+//
+// The request options are applied to a stand-in aws-sdk-go request, whose completion
+// handlers run once the call returns, with the outcome of the v2 call filled in.
+func call[In, Out, InV2, OutV2 any](
+	ctx context.Context,
+	name string,
+	input *In,
+	opts []request.Option,
+	do func(context.Context, *InV2, ...func(*s3v2.Options)) (*OutV2, error),
+	output *Out,
+) (*Out, error) {
+	r := &request.Request{
+		Operation:   &request.Operation{Name: name},
+		HTTPRequest: &http.Request{Header: http.Header{}},
+		Params:      input,
+		Data:        output,
+	}
+	r.SetContext(ctx)
+	r.ApplyOptions(opts...)
+
+	in := new(InV2)
+	convert(in, input)
+
+	out, err := do(r.Context(), in, options(r)...)
+
+	metadata := middleware.Metadata{}
+	if out != nil {
+		convert(output, out)
+		if m, ok := reflect.ValueOf(out).Elem().FieldByName("ResultMetadata").Interface().(middleware.Metadata); ok {
+			metadata = m
+		}
+	}
+
+	complete(r, metadata, err)
+
+	return output, r.Error
+}
+
+// options translates the settings of the stand-in request into options of the v2 client.
+func options(r *request.Request) []func(*s3v2.Options) {
+	optFns := []func(*s3v2.Options){}
+
+	if r.Retryer != nil {
+		attempts := r.Retryer.MaxRetries() + 1
+		optFns = append(optFns, func(o *s3v2.Options) {
+			o.RetryMaxAttempts = attempts
+		})
+	}
+
+	for header, values := range r.HTTPRequest.Header {
+		for _, value := range values {
+			header, value := header, value
+			optFns = append(optFns, func(o *s3v2.Options) {
+				o.APIOptions = append(o.APIOptions, smithyhttp.AddHeaderValue(header, value))
+			})
+		}
+	}
+
+	return optFns
+}
+
+// complete fills the outcome of the v2 call into the stand-in request and runs its completion handlers.
+func complete(r *request.Request, metadata middleware.Metadata, err error) {
+	var response *http.Response
+	if raw, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+		response = raw.Response
+	}
+	r.RequestID, _ = awsmiddleware.GetRequestIDMetadata(metadata)
+
+	attempts := 0
+	if results, ok := retry.GetAttemptResults(metadata); ok {
+		attempts = len(results.Results)
+	}
+
+	var rerr *awshttp.ResponseError
+	if errors.As(err, &rerr) {
+		response = rerr.Response.Response
+		r.RequestID = rerr.ServiceRequestID()
+	}
+
+	var aerr *retry.MaxAttemptsError
+	if errors.As(err, &aerr) {
+		attempts = aerr.Attempt
+	}
+
+	if attempts > 1 {
+		r.RetryCount = attempts - 1
+	}
+
+	if response != nil {
+		r.HTTPResponse = response
+		if response.Request != nil {
+			r.HTTPRequest.ContentLength = response.Request.ContentLength
+		}
+	}
+
+	r.Error = awsError(err, r)
+	r.Handlers.Complete.Run(r)
+}
+
+// awsError converts an error of the v2 client into the error aws-sdk-go would have returned.
+func awsError(err error, r *request.Request) error {
+	if err == nil {
+		return nil
+	}
+
+	code, message := request.ErrCodeRequestError, err.Error()
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code, message = apiErr.ErrorCode(), apiErr.ErrorMessage()
+	}
+	if errors.Is(err, context.Canceled) {
+		code = request.CanceledErrorCode
+	}
+
+	if r.HTTPResponse == nil {
+		return awserr.New(code, message, err)
+	}
+
+	return awserr.NewRequestFailure(awserr.New(code, message, err), r.HTTPResponse.StatusCode, r.RequestID)
+}
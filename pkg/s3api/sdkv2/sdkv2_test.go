@@ -0,0 +1,105 @@
+package sdkv2_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	s3v2 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/bucket"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/errs"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/s3api/sdkv2"
+)
+
+func Test_API(t *testing.T) {
+	server := mock.Start(t)
+
+	api := sdkv2.NewFromConfig(awsv2.Config{
+		Region: "us-east-1",
+		Credentials: awsv2.CredentialsProviderFunc(func(ctx context.Context) (awsv2.Credentials, error) {
+			return awsv2.Credentials{AccessKeyID: "id", SecretAccessKey: "secret"}, nil
+		}),
+	}, func(o *s3v2.Options) {
+		o.BaseEndpoint = awsv2.String(server.URL)
+		o.UsePathStyle = true
+	})
+
+	client, err := sthree.Open(nil, sthree.WithAPI(api))
+	if err != nil {
+		t.Fatalf("failed to open client - %v", err.Error())
+	}
+
+	var last sthree.Operation
+	client.Use(func(next sthree.Handler) sthree.Handler {
+		return func(ctx context.Context, op *sthree.Operation) error {
+			err := next(ctx, op)
+			last = *op
+			return err
+		}
+	})
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	if _, err := client.Bucket("assets").Put("logo.png", "logo", objects.Put{Config: objects.ObjectDetails{ContentType: "application/json"}}); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if last.StatusCode != 200 || last.RequestID == "" || last.BytesSent == 0 {
+		t.Errorf("expected the outcome to be recorded - %+v", last)
+	}
+	if data, ok := server.Object("assets", "logo.png"); !ok || string(data) != "logo" {
+		t.Errorf("expected object to be stored - %s", data)
+	}
+
+	output, err := client.Bucket("assets").Get("logo.png")
+	if err != nil {
+		t.Fatalf("failed to get object - %v", err.Error())
+	}
+	body, _ := io.ReadAll(output.Body)
+	if string(body) != "logo" || output.Size != 4 || output.ETag == "" {
+		t.Errorf("unexpected object - %s %+v", body, output)
+	}
+
+	list, err := client.Bucket("assets").List()
+	if err != nil {
+		t.Fatalf("failed to list objects - %v", err.Error())
+	}
+	if len(list.Objects) != 1 || list.Objects[0].Key != "logo.png" || list.Objects[0].Size != 4 {
+		t.Errorf("unexpected listing - %+v", list)
+	}
+
+	buckets, err := client.Buckets.List()
+	if err != nil || len(buckets) != 1 || buckets[0].Name != "assets" {
+		t.Errorf("unexpected buckets - %+v %v", buckets, err)
+	}
+
+	cors := bucket.Cors{Bucket: "assets", Rules: []bucket.CorsRule{{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}}}
+	if err := client.For("assets").SetCors(cors); err != nil {
+		t.Errorf("failed to set cors - %v", err.Error())
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := client.Bucket("assets").Get("missing.png")
+		if !errors.Is(err, errs.ErrNotFound) || errs.StatusCode(err) != 404 || errs.RequestID(err) == "" {
+			t.Errorf("expected not found error - %v", err)
+		}
+
+		if _, err := client.Buckets.Create("assets"); !errors.Is(err, errs.ErrAlreadyExists) {
+			t.Errorf("expected already exists error - %v", err)
+		}
+	})
+
+	if _, err := client.Bucket("assets").Delete("logo.png"); err != nil {
+		t.Errorf("failed to delete object - %v", err.Error())
+	}
+	if _, err := client.Buckets.Delete("assets"); err != nil {
+		t.Errorf("failed to delete bucket - %v", err.Error())
+	}
+}
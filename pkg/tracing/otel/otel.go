@@ -0,0 +1,80 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/avila-r/sthree/pkg/tracing"
+)
+
+// ScopeName is the instrumentation scope of the spans recorded by sthree.
+const ScopeName = "github.com/avila-r/sthree"
+
+// Tracer adapts an OpenTelemetry tracer provider to `tracing.Tracer`.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New creates a tracer recording client spans through the given provider.
+//
+// @param provider The OpenTelemetry tracer provider (e.g., `otel.GetTracerProvider()`).
+// @return A pointer to a new `Tracer`.
+func New(provider trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: provider.Tracer(ScopeName)}
+}
+
+// Start starts an OpenTelemetry client span as a child of the span carried by ctx, if any.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attrs)...),
+	)
+
+	return ctx, &Span{span: span}
+}
+
+// Span adapts an OpenTelemetry span to `tracing.Span`.
+type Span struct {
+	span trace.Span
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...tracing.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+// End records the error, if any, sets the status of the span accordingly and ends it.
+func (s *Span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+
+	s.span.End()
+}
+
+func convert(attrs []tracing.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+
+	return kvs
+}
@@ -0,0 +1,52 @@
+package otel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/avila-r/sthree/pkg/middleware"
+	"github.com/avila-r/sthree/pkg/tracing"
+	"github.com/avila-r/sthree/pkg/tracing/otel"
+)
+
+func Test_Tracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := otel.New(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	chain := &middleware.Chain{}
+	chain.Use(tracing.Middleware(tracer))
+
+	ctx, parent := tracer.Start(context.Background(), "handler")
+	op := &middleware.Operation{Name: middleware.GetObject, Bucket: "assets", Key: "logo.png"}
+	chain.Do(ctx, op, func(ctx context.Context, op *middleware.Operation) error {
+		op.StatusCode = 404
+		return errors.New("NoSuchKey")
+	})
+	parent.End(nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %v", len(spans))
+	}
+
+	get := spans[0]
+	if get.Name() != "S3.GetObject" || get.Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Errorf("expected operation span to be a child of the caller's span - %v", get.Name())
+	}
+	if get.Status().Code != codes.Error {
+		t.Errorf("expected failed span - %v", get.Status())
+	}
+
+	attrs := map[string]any{}
+	for _, kv := range get.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	if attrs[tracing.AttrBucket] != "assets" || attrs[tracing.AttrKey] != "logo.png" || attrs[tracing.AttrStatusCode] != int64(404) {
+		t.Errorf("unexpected attributes - %v", attrs)
+	}
+}
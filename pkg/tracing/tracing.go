@@ -0,0 +1,154 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/avila-r/sthree/pkg/middleware"
+)
+
+// Tracer starts the spans recorded for S3 operations.
+//
+// Implementations adapt a tracing library (see the `otel` sub-package for OpenTelemetry).
+// The context returned by Start must carry the span, so spans started from it nest under it.
+type Tracer interface {
+	// Start starts a span as a child of the span carried by ctx, if any.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attrs ...Attribute)
+
+	// End ends the span, marking it as failed if err is not nil.
+	End(err error)
+}
+
+// Attribute is a key-value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String creates a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int creates an integer attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Int64 creates an integer attribute.
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Keys of the attributes recorded on every span.
+const (
+	AttrSystem        = "rpc.system"
+	AttrService       = "rpc.service"
+	AttrMethod        = "rpc.method"
+	AttrBucket        = "aws.s3.bucket"
+	AttrKey           = "aws.s3.key"
+	AttrRequestID     = "aws.request_id"
+	AttrStatusCode    = "http.response.status_code"
+	AttrRetries       = "sthree.retries"
+	AttrBytesSent     = "sthree.bytes_sent"
+	AttrBytesReceived = "sthree.bytes_received"
+)
+
+// Noop is a tracer that records nothing. Clients without a tracer behave as if they used it,
+// without even running the tracing middleware.
+var Noop Tracer = noop{}
+
+type noop struct{}
+
+func (noop) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noop{}
+}
+
+func (noop) SetAttributes(...Attribute) {}
+
+func (noop) End(error) {}
+
+// Middleware returns a middleware that records a span named after each operation (e.g., "S3.PutObject").
+//
+// Composite operations, such as multipart uploads, also record a child span for each
+// SDK request they issue (e.g., "S3.UploadPart").
+//
+// @param tracer The tracer spans are started with.
+// @return A middleware tracing every operation.
+func Middleware(tracer Tracer) middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, op *middleware.Operation) error {
+			ctx, span := tracer.Start(ctx, "S3."+op.Name, start(op.Name, op.Bucket, op.Key)...)
+			op.Options = append(op.Options, requests(tracer, op))
+
+			err := next(ctx, op)
+
+			span.SetAttributes(end(op)...)
+			span.End(err)
+
+			return err
+		}
+	}
+}
+
+// requests returns a request option that traces the SDK requests of a composite operation.
+// The request performing a simple operation is already traced by the operation span.
+func requests(tracer Tracer, op *middleware.Operation) request.Option {
+	return func(r *request.Request) {
+		if r.Operation.Name == op.Name {
+			return
+		}
+
+		ctx, span := tracer.Start(r.Context(), "S3."+r.Operation.Name, start(r.Operation.Name, op.Bucket, op.Key)...)
+		r.SetContext(ctx)
+
+		// Record pushes its handler first, so the sub-operation is filled in before the span ends.
+		sub := &middleware.Operation{Name: r.Operation.Name}
+		middleware.Record(sub)(r)
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			span.SetAttributes(end(sub)...)
+			span.End(r.Error)
+		})
+	}
+}
+
+func start(name, bucket, key string) []Attribute {
+	attrs := []Attribute{
+		String(AttrSystem, "aws-api"),
+		String(AttrService, "S3"),
+		String(AttrMethod, name),
+	}
+	if bucket != "" {
+		attrs = append(attrs, String(AttrBucket, bucket))
+	}
+	if key != "" {
+		attrs = append(attrs, String(AttrKey, key))
+	}
+
+	return attrs
+}
+
+func end(op *middleware.Operation) []Attribute {
+	attrs := []Attribute{Int(AttrRetries, op.Retries)}
+	if op.StatusCode != 0 {
+		attrs = append(attrs, Int(AttrStatusCode, op.StatusCode))
+	}
+	if op.RequestID != "" {
+		attrs = append(attrs, String(AttrRequestID, op.RequestID))
+	}
+	if op.BytesSent > 0 {
+		attrs = append(attrs, Int64(AttrBytesSent, op.BytesSent))
+	}
+	if op.BytesReceived > 0 {
+		attrs = append(attrs, Int64(AttrBytesReceived, op.BytesReceived))
+	}
+
+	return attrs
+}
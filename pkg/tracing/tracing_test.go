@@ -0,0 +1,130 @@
+package tracing_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/tracing"
+)
+
+type span struct {
+	name   string
+	parent string
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	spans []*span
+}
+
+type key struct{}
+
+func (r *recorder) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &span{name: name, attrs: map[string]any{}}
+	if parent, ok := ctx.Value(key{}).(*span); ok {
+		s.parent = parent.name
+	}
+	r.spans = append(r.spans, s)
+	s.SetAttributes(attrs...)
+
+	return context.WithValue(ctx, key{}, s), s
+}
+
+func (s *span) SetAttributes(attrs ...tracing.Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *span) End(err error) {
+	s.err, s.ended = err, true
+}
+
+func Test_Middleware(t *testing.T) {
+	tracer := &recorder{}
+	client, _ := mock.Client(t, sthree.WithTracer(tracer))
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	if _, err := client.Bucket("assets").Put("logo.png", "logo"); err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	client.Bucket("assets").Get("missing.png")
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected a span per operation, got %v", len(tracer.spans))
+	}
+
+	put := tracer.spans[1]
+	if put.name != "S3.PutObject" || put.attrs[tracing.AttrBucket] != "assets" || put.attrs[tracing.AttrKey] != "logo.png" {
+		t.Errorf("unexpected span - %+v", put)
+	}
+	if put.attrs[tracing.AttrStatusCode] != int64(200) || put.attrs[tracing.AttrBytesSent] == nil || !put.ended {
+		t.Errorf("expected span to carry the outcome - %+v", put.attrs)
+	}
+
+	if get := tracer.spans[2]; get.err == nil || get.attrs[tracing.AttrStatusCode] != int64(404) {
+		t.Errorf("expected failed span - %+v", get)
+	}
+
+	t.Run("composite operations nest", func(t *testing.T) {
+		tracer.spans = nil
+
+		bucket := client.Bucket("assets")
+		if _, err := bucket.Upload(objects.Upload{Body: strings.NewReader("readme"), ObjectDetails: objects.ObjectDetails{Key: "readme.md"}}); err != nil {
+			t.Fatalf("failed to upload object - %v", err.Error())
+		}
+
+		if len(tracer.spans) != 2 {
+			t.Fatalf("expected an operation span and a request span, got %v", len(tracer.spans))
+		}
+		if upload, put := tracer.spans[0], tracer.spans[1]; upload.name != "S3.Upload" || put.name != "S3.PutObject" || put.parent != "S3.Upload" || !put.ended {
+			t.Errorf("expected request span to be a child of the upload span - %+v", put)
+		}
+	})
+
+	t.Run("ranges of downloads are traced once", func(t *testing.T) {
+		bucket := client.Bucket("assets")
+		if _, err := bucket.Put("archive.bin", strings.Repeat("s", 3000)); err != nil {
+			t.Fatalf("failed to put object - %v", err.Error())
+		}
+		tracer.spans = nil
+
+		if _, err := bucket.Download("archive.bin", &buffer{}, objects.Download{PartSize: 1 << 10, Concurrency: 1}); err != nil {
+			t.Fatalf("failed to download object - %v", err.Error())
+		}
+
+		names := []string{}
+		for _, s := range tracer.spans {
+			names = append(names, s.name)
+		}
+		if strings.Join(names, ",") != "S3.Download,S3.GetObject,S3.GetObject,S3.GetObject" {
+			t.Errorf("expected a span per range - %v", names)
+		}
+	})
+}
+
+// buffer is an in-memory io.WriterAt, written by a single goroutine.
+type buffer struct {
+	data []byte
+}
+
+func (b *buffer) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(b.data) {
+		b.data = append(b.data, make([]byte, end-len(b.data))...)
+	}
+
+	return copy(b.data[off:], p), nil
+}
@@ -0,0 +1,208 @@
+package sthree
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+// RegionRetryInterval is how long a bucket whose region could not be discovered is served by
+// the default client before its region is looked up again.
+const RegionRetryInterval = time.Minute
+
+// regions discovers the region of each bucket and routes it to a lazily created,
+// per-region S3 client, so cross-region buckets don't fail with PermanentRedirect.
+//
+// Regions are discovered on the first request of each bucket, with the context of that request.
+// Both the bucket regions and the per-region clients are cached for the lifetime of the `Sthree`
+// instance, while failed lookups are cached for `RegionRetryInterval`.
+type regions struct {
+	mu       sync.Mutex
+	provider client.ConfigProvider
+	config   Config
+	fallback *s3.S3
+	buckets  map[string]string
+	failures map[string]time.Time
+	clients  map[string]*s3.S3
+}
+
+// newRegions creates a region router whose default client is `fallback`.
+func newRegions(provider client.ConfigProvider, cfg Config, fallback *s3.S3) *regions {
+	return &regions{
+		provider: provider,
+		config:   cfg,
+		fallback: fallback,
+		buckets:  map[string]string{},
+		failures: map[string]time.Time{},
+		clients: map[string]*s3.S3{
+			aws.StringValue(fallback.Config.Region): fallback,
+		},
+	}
+}
+
+// region returns the region of the bucket, discovering and caching it on first use.
+//
+// Discovery uses HeadBucket, which reports the region even when the bucket lives
+// elsewhere, and falls back to GetBucketLocation. Failures are cached for `RegionRetryInterval`,
+// unless the context was cancelled.
+func (r *regions) region(ctx context.Context, bucket string) (string, error) {
+	r.mu.Lock()
+	region, ok := r.buckets[bucket]
+	until, failed := r.failures[bucket]
+	r.mu.Unlock()
+
+	if ok {
+		return region, nil
+	}
+	if failed && time.Now().Before(until) {
+		return "", errRegionUnknown
+	}
+
+	region, err := s3manager.GetBucketRegionWithClient(ctx, r.fallback, bucket)
+	if err != nil {
+		output, locErr := r.fallback.GetBucketLocationWithContext(ctx, &s3.GetBucketLocationInput{
+			Bucket: aws.String(bucket),
+		})
+		if locErr != nil {
+			if ctx.Err() == nil {
+				r.mu.Lock()
+				r.failures[bucket] = time.Now().Add(RegionRetryInterval)
+				r.mu.Unlock()
+			}
+			return "", err
+		}
+		region = s3.NormalizeBucketLocation(aws.StringValue(output.LocationConstraint))
+	}
+
+	r.mu.Lock()
+	r.buckets[bucket] = region
+	delete(r.failures, bucket)
+	r.mu.Unlock()
+
+	return region, nil
+}
+
+// errRegionUnknown is returned while a failed region lookup is cached.
+var errRegionUnknown = errors.New("sthree: bucket region unknown")
+
+// client returns the S3 client for the bucket's region.
+// When the region cannot be discovered, the default client is returned.
+func (r *regions) client(ctx context.Context, bucket string) *s3.S3 {
+	region, err := r.region(ctx, bucket)
+	if err != nil || region == "" {
+		return r.fallback
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sdk, ok := r.clients[region]
+	if !ok {
+		sdk = s3.New(r.provider, r.config.ToAWSConfig(), &aws.Config{
+			Region: aws.String(region),
+		})
+		r.clients[region] = sdk
+	}
+
+	return sdk
+}
+
+// api returns the S3 API of the bucket, which discovers its region on its first request.
+func (r *regions) api(bucket string) s3api.API {
+	return &routed{regions: r, bucket: bucket}
+}
+
+// routed is the S3 API of a single bucket, routed to the client of its region.
+type routed struct {
+	regions *regions
+	bucket  string
+}
+
+// Resolve returns the client of the bucket's region, discovering it with the given context.
+func (r *routed) Resolve(ctx aws.Context) s3api.API {
+	return r.regions.client(ctx, r.bucket)
+}
+
+func (r *routed) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	return r.regions.client(ctx, r.bucket).GetObjectWithContext(ctx, input, opts...)
+}
+
+func (r *routed) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	return r.regions.client(ctx, r.bucket).PutObjectWithContext(ctx, input, opts...)
+}
+
+func (r *routed) DeleteObjectWithContext(ctx aws.Context, input *s3.DeleteObjectInput, opts ...request.Option) (*s3.DeleteObjectOutput, error) {
+	return r.regions.client(ctx, r.bucket).DeleteObjectWithContext(ctx, input, opts...)
+}
+
+func (r *routed) ListObjectsV2WithContext(ctx aws.Context, input *s3.ListObjectsV2Input, opts ...request.Option) (*s3.ListObjectsV2Output, error) {
+	return r.regions.client(ctx, r.bucket).ListObjectsV2WithContext(ctx, input, opts...)
+}
+
+func (r *routed) CreateBucketWithContext(ctx aws.Context, input *s3.CreateBucketInput, opts ...request.Option) (*s3.CreateBucketOutput, error) {
+	// A bucket being created has no region to discover yet.
+	return r.regions.fallback.CreateBucketWithContext(ctx, input, opts...)
+}
+
+func (r *routed) DeleteBucketWithContext(ctx aws.Context, input *s3.DeleteBucketInput, opts ...request.Option) (*s3.DeleteBucketOutput, error) {
+	return r.regions.client(ctx, r.bucket).DeleteBucketWithContext(ctx, input, opts...)
+}
+
+func (r *routed) ListBucketsWithContext(ctx aws.Context, input *s3.ListBucketsInput, opts ...request.Option) (*s3.ListBucketsOutput, error) {
+	return r.regions.fallback.ListBucketsWithContext(ctx, input, opts...)
+}
+
+func (r *routed) HeadBucketWithContext(ctx aws.Context, input *s3.HeadBucketInput, opts ...request.Option) (*s3.HeadBucketOutput, error) {
+	return r.regions.client(ctx, r.bucket).HeadBucketWithContext(ctx, input, opts...)
+}
+
+func (r *routed) PutBucketCorsWithContext(ctx aws.Context, input *s3.PutBucketCorsInput, opts ...request.Option) (*s3.PutBucketCorsOutput, error) {
+	return r.regions.client(ctx, r.bucket).PutBucketCorsWithContext(ctx, input, opts...)
+}
+
+// Region returns the region of the given bucket.
+//
+// With region discovery enabled the result is cached; otherwise it is
+// the region of the default client.
+//
+// @param bucket The name of the bucket.
+// @return The region of the bucket, or an error if it cannot be discovered.
+func (m *Sthree) Region(bucket string) (string, error) {
+	return m.RegionWithContext(context.Background(), bucket)
+}
+
+// RegionWithContext is the same as Region, with the addition of a context
+// used to cancel the lookup or apply a deadline to it.
+//
+// @param ctx The context of the lookup.
+// @param bucket The name of the bucket.
+// @return The region of the bucket, or an error if it cannot be discovered.
+func (m *Sthree) RegionWithContext(ctx context.Context, bucket string) (string, error) {
+	if m.regions == nil && m.Sdk == nil {
+		return m.Config.Region, nil
+	}
+	if m.regions == nil {
+		return aws.StringValue(m.Sdk.Config.Region), nil
+	}
+
+	return m.regions.region(ctx, bucket)
+}
+
+// sdkFor returns the S3 API that should serve requests for the given bucket.
+// With region discovery enabled, it routes each request to the client of the bucket's region.
+func (m *Sthree) sdkFor(bucket string) s3api.API {
+	if m.regions == nil {
+		return m.API
+	}
+
+	return m.regions.api(bucket)
+}
@@ -0,0 +1,89 @@
+package sthree_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+func Test_RegionDiscovery(t *testing.T) {
+	var (
+		heads   atomic.Int32
+		mu      sync.Mutex
+		regions []string
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/unknown") {
+			if r.Method == http.MethodHead {
+				heads.Add(1)
+			}
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+			w.Header().Set("X-Amz-Bucket-Region", "eu-central-1")
+			return
+		}
+
+		// The credential scope of the signature names the region of the client.
+		mu.Lock()
+		_, scope, _ := strings.Cut(r.Header.Get("Authorization"), "Credential=")
+		regions = append(regions, strings.Split(scope, "/")[2])
+		mu.Unlock()
+		w.Write([]byte("logo"))
+	})
+
+	client, _ := mock.Connect(t, handler,
+		sthree.WithRegionDiscovery(),
+	)
+
+	first := client.Bucket("cross-region")
+	second := client.For("cross-region").Objects()
+	if n := heads.Load(); n != 0 {
+		t.Errorf("expected modules to be created without lookups, got %v", n)
+	}
+
+	for _, module := range []*objects.Module{first, second} {
+		if _, err := module.GetWithContext(context.Background(), "logo.png"); err != nil {
+			t.Fatalf("failed to get object - %v", err.Error())
+		}
+	}
+
+	if len(regions) != 2 || regions[0] != "eu-central-1" || regions[1] != "eu-central-1" {
+		t.Errorf("bucket was not routed to its region - %v", regions)
+	}
+	if n := heads.Load(); n != 1 {
+		t.Errorf("expected bucket region to be discovered once, got %v lookups", n)
+	}
+
+	t.Run("lookups use the context of the request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := client.Bucket("unknown").GetWithContext(ctx, "logo.png"); err == nil {
+			t.Errorf("expected cancelled request to fail")
+		}
+		if n := heads.Load(); n != 1 {
+			t.Errorf("expected no lookup with a cancelled context, got %v", n-1)
+		}
+	})
+
+	t.Run("failed lookups are cached", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			client.Bucket("unknown").Get("logo.png")
+		}
+
+		if n := heads.Load(); n != 2 {
+			t.Errorf("expected a single lookup of the unknown bucket, got %v", n-1)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package sthree
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/avila-r/sthree/internal/bucket"
+	"github.com/avila-r/sthree/internal/objects"
+)
+
+var (
+	// ErrClientNotFound is returned when no client is registered under the requested name.
+	ErrClientNotFound = errors.New("sthree: client not found")
+
+	// ErrClientExists is returned when registering a name that is already taken.
+	ErrClientExists = errors.New("sthree: client already registered")
+
+	// ErrNilClient is returned when registering a nil client.
+	ErrNilClient = errors.New("sthree: nil client")
+
+	// ErrNoRoute is returned when a bucket matches no route and the registry has no default client.
+	ErrNoRoute = errors.New("sthree: no client routes bucket")
+)
+
+// Registry holds named `Sthree` clients, each with its own configuration and credentials,
+// and routes buckets to them by name pattern.
+//
+// It is meant for services that talk to several AWS accounts or S3-compatible providers at once.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	clients  map[string]*Sthree
+	routes   []route
+	fallback string
+}
+
+// route maps a bucket-name pattern to the name of a registered client.
+type route struct {
+	pattern string
+	client  string
+}
+
+// NewRegistry creates an empty client registry.
+//
+// @return A pointer to an empty `Registry`.
+func NewRegistry() *Registry {
+	return &Registry{
+		clients: map[string]*Sthree{},
+	}
+}
+
+// Register adds an already connected client under the given name.
+//
+// @param name The unique name of the client (e.g., "prod-account").
+// @param client The client to register.
+// @return ErrNilClient if the client is nil, or ErrClientExists if the name is already taken.
+func (r *Registry) Register(name string, client *Sthree) error {
+	if client == nil {
+		return fmt.Errorf("%w: %q", ErrNilClient, name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[name]; ok {
+		return fmt.Errorf("%w: %q", ErrClientExists, name)
+	}
+	r.clients[name] = client
+
+	return nil
+}
+
+// Configure creates a new client through `Session` and registers it under the given name.
+//
+// @param name The unique name of the client.
+// @param opts The settings (a `Config` or functional options) of the client.
+// @return An error if the client cannot be created or the name is already taken.
+func (r *Registry) Configure(name string, opts ...Option) error {
+	client, err := Session(opts...)
+	if err != nil {
+		return fmt.Errorf("sthree: configuring client %q: %w", name, err)
+	}
+
+	return r.Register(name, client)
+}
+
+// Route sends every bucket whose name matches the pattern to the named client.
+//
+// Patterns use `path.Match` syntax (e.g., "logs-*"). Routes are evaluated in the
+// order they were added, and the first match wins.
+//
+// @param pattern The bucket-name pattern.
+// @param name The name of a registered client.
+// @return An error if the pattern is malformed or the client is not registered.
+func (r *Registry) Route(pattern, name string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("sthree: invalid route pattern %q: %w", pattern, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrClientNotFound, name)
+	}
+	r.routes = append(r.routes, route{pattern: pattern, client: name})
+
+	return nil
+}
+
+// Default sets the client used for buckets that match no route.
+//
+// @param name The name of a registered client.
+// @return ErrClientNotFound if the client is not registered.
+func (r *Registry) Default(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrClientNotFound, name)
+	}
+	r.fallback = name
+
+	return nil
+}
+
+// Client returns the client registered under the given name.
+//
+// @param name The name of the client.
+// @return The client, or ErrClientNotFound.
+func (r *Registry) Client(name string) (*Sthree, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, ok := r.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrClientNotFound, name)
+	}
+
+	return client, nil
+}
+
+// Resolve returns the client responsible for the given bucket.
+//
+// @param bucket The name of the bucket.
+// @return The client of the first matching route, the default client, or ErrNoRoute.
+func (r *Registry) Resolve(bucket string) (*Sthree, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, route := range r.routes {
+		if ok, _ := path.Match(route.pattern, bucket); ok {
+			return r.clients[route.client], nil
+		}
+	}
+
+	if r.fallback != "" {
+		return r.clients[r.fallback], nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrNoRoute, bucket)
+}
+
+// Bucket returns an instance of the `objects.Module` for the specified bucket,
+// wired to the client that bucket is routed to.
+//
+// @param name The name of the bucket to associate with the returned module.
+// @return An instance of `objects.Module`, or ErrNoRoute if no client handles the bucket.
+func (r *Registry) Bucket(name string) (*objects.Module, error) {
+	client, err := r.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Bucket(name), nil
+}
+
+// For returns an instance of the `bucket.Module` for the specified bucket,
+// wired to the client that bucket is routed to.
+//
+// @param name The name of the bucket to associate with the returned module.
+// @return An instance of `bucket.Module`, or ErrNoRoute if no client handles the bucket.
+func (r *Registry) For(name string) (*bucket.Module, error) {
+	client, err := r.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.For(name), nil
+}
@@ -0,0 +1,70 @@
+package sthree_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/avila-r/sthree"
+)
+
+func Test_Registry(t *testing.T) {
+	sess := session.Must(session.NewSession())
+
+	registry := sthree.NewRegistry()
+	prod := sthree.Connect(sess, sthree.WithRegion("us-east-1"))
+	minio := sthree.Connect(sess, sthree.WithEndpoint("http://localhost:9000"), sthree.WithPathStyle())
+
+	if err := registry.Register("prod", prod); err != nil {
+		t.Fatalf("failed to register client - %v", err.Error())
+	}
+	if err := registry.Register("minio", minio); err != nil {
+		t.Fatalf("failed to register client - %v", err.Error())
+	}
+	if err := registry.Register("prod", minio); !errors.Is(err, sthree.ErrClientExists) {
+		t.Errorf("expected duplicated registration to fail - %v", err)
+	}
+	if err := registry.Register("staging", nil); !errors.Is(err, sthree.ErrNilClient) {
+		t.Errorf("expected nil client registration to fail - %v", err)
+	}
+
+	if err := registry.Route("logs-*", "prod"); err != nil {
+		t.Fatalf("failed to add route - %v", err.Error())
+	}
+	if err := registry.Route("dev-*", "unknown"); !errors.Is(err, sthree.ErrClientNotFound) {
+		t.Errorf("expected route to unknown client to fail - %v", err)
+	}
+
+	t.Run("bucket is routed by pattern", func(t *testing.T) {
+		module, err := registry.Bucket("logs-prod")
+		if err != nil {
+			t.Fatalf("failed to resolve bucket - %v", err.Error())
+		}
+
+		if module.Bucket != "logs-prod" || module.Sdk != prod.Sdk {
+			t.Errorf("bucket was not routed to the prod client")
+		}
+	})
+
+	t.Run("unmatched bucket without default", func(t *testing.T) {
+		if _, err := registry.Bucket("assets"); !errors.Is(err, sthree.ErrNoRoute) {
+			t.Errorf("expected no route error - %v", err)
+		}
+	})
+
+	t.Run("unmatched bucket with default", func(t *testing.T) {
+		if err := registry.Default("minio"); err != nil {
+			t.Fatalf("failed to set default client - %v", err.Error())
+		}
+
+		module, err := registry.For("assets")
+		if err != nil {
+			t.Fatalf("failed to resolve bucket - %v", err.Error())
+		}
+
+		if module.Sdk != minio.Sdk {
+			t.Errorf("bucket was not routed to the default client")
+		}
+	})
+}
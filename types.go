@@ -0,0 +1,36 @@
+package sthree
+
+import (
+	"github.com/avila-r/sthree/internal/buckets"
+	"github.com/avila-r/sthree/internal/objects"
+)
+
+// Object is an object retrieved from S3, returned by `Get`.
+type Object = objects.Object
+
+// ObjectInfo describes an object stored in S3, without its content.
+type ObjectInfo = objects.ObjectInfo
+
+// DeleteResult describes an object deleted from S3, returned by `Delete`.
+type DeleteResult = objects.DeleteResult
+
+// UploadResult describes an object uploaded in multiple parts, returned by `Upload`.
+type UploadResult = objects.UploadResult
+
+// ListPage is a single page of a bucket listing, returned by `List`.
+type ListPage = objects.ListPage
+
+// BucketInfo describes a bucket owned by the sender, returned by `Buckets.List` and `Buckets.Create`.
+type BucketInfo = buckets.BucketInfo
+
+// DeletedBucket describes a bucket deleted by `Buckets.Delete`.
+type DeletedBucket = buckets.DeletedBucket
+
+// UploadConfig tunes the multipart uploads of `Upload`, set through `Config.Upload`.
+type UploadConfig = objects.UploadConfig
+
+// DownloadConfig tunes the ranged downloads of `Download`, set through `Config.Download`.
+type DownloadConfig = objects.DownloadConfig
+
+// MaxUploadPartSize is the largest part size S3 accepts in a multipart upload (5 GiB).
+const MaxUploadPartSize int64 = 5 << 30
@@ -0,0 +1,77 @@
+package sthree_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/mock"
+)
+
+func Test_Results(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	created, err := client.Buckets.Create("assets")
+	if err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+	if created.Name != "assets" {
+		t.Errorf("unexpected bucket info - %+v", created)
+	}
+
+	details := objects.ObjectDetails{ContentType: "application/json", Metadata: map[string]string{"owner": "web"}}
+	info, err := client.Bucket("assets").Put("images/logo.json", "logo", objects.Put{Config: details})
+	if err != nil {
+		t.Fatalf("failed to put object - %v", err.Error())
+	}
+	if info.Bucket != "assets" || info.Key != "images/logo.json" || info.ETag == "" || info.Size != 4 {
+		t.Errorf("unexpected object info - %+v", info)
+	}
+
+	object, err := client.Bucket("assets").Get("images/logo.json")
+	if err != nil {
+		t.Fatalf("failed to get object - %v", err.Error())
+	}
+	defer object.Body.Close()
+
+	body, _ := io.ReadAll(object.Body)
+	if string(body) != "logo" || object.Size != 4 || object.ETag != info.ETag {
+		t.Errorf("unexpected object - %s %+v", body, object.ObjectInfo)
+	}
+	if object.ContentType != "application/json" || object.Metadata["Owner"] != "web" || object.LastModified.IsZero() {
+		t.Errorf("expected object details - %+v", object.ObjectInfo)
+	}
+
+	page, err := client.Bucket("assets").List(objects.List{Prefix: "images/"})
+	if err != nil {
+		t.Fatalf("failed to list objects - %v", err.Error())
+	}
+	if page.Bucket != "assets" || page.Prefix != "images/" || page.IsTruncated || len(page.Objects) != 1 || page.Objects[0].Key != "images/logo.json" {
+		t.Errorf("unexpected page - %+v", page)
+	}
+
+	buckets, err := client.Buckets.List()
+	if err != nil || len(buckets) != 1 || buckets[0].Name != "assets" {
+		t.Errorf("unexpected buckets - %+v %v", buckets, err)
+	}
+
+	uploaded, err := client.Bucket("assets").Upload(objects.Upload{
+		Body:          strings.NewReader("archive"),
+		ObjectDetails: objects.ObjectDetails{Key: "archive.txt", ContentType: "text/plain"},
+	})
+	if err != nil {
+		t.Fatalf("failed to upload object - %v", err.Error())
+	}
+	if uploaded.Bucket != "assets" || uploaded.Key != "archive.txt" || uploaded.ETag == "" || uploaded.ContentType != "text/plain" || uploaded.Location == "" {
+		t.Errorf("unexpected upload result - %+v", uploaded)
+	}
+
+	deleted, err := client.Bucket("assets").Delete("archive.txt")
+	if err != nil {
+		t.Fatalf("failed to delete object - %v", err.Error())
+	}
+	if deleted.Bucket != "assets" || deleted.Key != "archive.txt" {
+		t.Errorf("unexpected delete result - %+v", deleted)
+	}
+}
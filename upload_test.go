@@ -0,0 +1,116 @@
+package sthree_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/avila-r/sthree"
+	"github.com/avila-r/sthree/internal/objects"
+	"github.com/avila-r/sthree/pkg/mock"
+	"github.com/avila-r/sthree/pkg/s3api"
+)
+
+func Test_Upload(t *testing.T) {
+	client, server := mock.Client(t, sthree.WithUpload(sthree.UploadConfig{
+		PartSize:    s3manager.MinUploadPartSize,
+		Concurrency: 2,
+	}))
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	body := bytes.Repeat([]byte("sthree"), int(2*s3manager.MinUploadPartSize/6+1))
+	output, err := client.Bucket("assets").Upload(objects.Upload{
+		Body:          bytes.NewReader(body),
+		ObjectDetails: objects.ObjectDetails{Key: "archive.bin"},
+	})
+	if err != nil {
+		t.Fatalf("failed to upload object - %v", err.Error())
+	}
+
+	stored, _ := server.Object("assets", "archive.bin")
+	if !bytes.Equal(stored, body) || output.UploadID == "" {
+		t.Errorf("unexpected upload - %d bytes stored, %+v", len(stored), output)
+	}
+
+	parts := 0
+	for _, request := range server.Requests() {
+		if strings.Contains(request, "?partNumber=") {
+			parts++
+		}
+	}
+	if parts != 3 {
+		t.Errorf("expected 3 parts, got %d", parts)
+	}
+}
+
+func Test_Upload_PartSize(t *testing.T) {
+	cases := []struct {
+		size     int64
+		expected int64
+	}{
+		{0, s3manager.MinUploadPartSize},
+		{1 << 30, s3manager.MinUploadPartSize},
+		{100 << 30, 100<<30/s3manager.MaxUploadParts + 1},
+	}
+
+	if size := objects.PartSize(0, 100<<30, 0); size != 100<<30/s3manager.MaxUploadParts+1 {
+		t.Errorf("expected zero part sizes to stand for the default - %d", size)
+	}
+
+	for _, c := range cases {
+		size := objects.PartSize(s3manager.MinUploadPartSize, c.size, s3manager.MaxUploadParts)
+		if size != c.expected {
+			t.Errorf("unexpected part size for %d bytes - %d", c.size, size)
+		}
+		if c.size > 0 && (c.size+size-1)/size > s3manager.MaxUploadParts {
+			t.Errorf("part size %d exceeds the part limit for %d bytes", size, c.size)
+		}
+	}
+}
+
+func Test_Upload_Unsupported(t *testing.T) {
+	client, _ := mock.Client(t)
+
+	module := objects.Module{Bucket: "assets", Sdk: struct{ s3api.API }{client.Sdk}}
+	module.Uploader = objects.NewUploader(module.Sdk, sthree.UploadConfig{})
+
+	if _, err := module.Upload(objects.Upload{Body: strings.NewReader("sthree")}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected unsupported error, got %v", err)
+	}
+}
+
+func Test_Upload_Config(t *testing.T) {
+	_, err := sthree.Open(session.Must(session.NewSession()),
+		sthree.WithUpload(sthree.UploadConfig{PartSize: 1 << 20, Concurrency: -1}),
+	)
+
+	var cfgErr *sthree.ConfigError
+	if !errors.As(err, &cfgErr) || len(cfgErr.Fields) != 2 {
+		t.Errorf("expected invalid part size and concurrency - %v", err)
+	}
+}
+
+func Test_Upload_Uploader(t *testing.T) {
+	client, server := mock.Client(t)
+
+	if _, err := client.Buckets.Create("assets"); err != nil {
+		t.Fatalf("failed to create bucket - %v", err.Error())
+	}
+
+	// Modules built by hand, with a zero or partially configured uploader, use their API.
+	module := objects.Module{Bucket: "assets", Sdk: client.Sdk, Uploader: s3manager.Uploader{Concurrency: 1}}
+	if _, err := module.Upload(objects.Upload{Body: strings.NewReader("sthree"), ObjectDetails: objects.ObjectDetails{Key: "readme.md"}}); err != nil {
+		t.Fatalf("failed to upload object - %v", err.Error())
+	}
+
+	if stored, _ := server.Object("assets", "readme.md"); string(stored) != "sthree" {
+		t.Errorf("unexpected body stored - %q", stored)
+	}
+}